@@ -0,0 +1,614 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type config struct {
+	Dir      string
+	TypeName string
+	FuncName string
+	Tag      string
+	Naming   string
+	Output   string
+}
+
+type generator struct {
+	config
+	naming     func(string) string
+	pkgName    string
+	types      map[string]ast.Expr
+	pending    []string
+	generated  map[string]bool
+	fieldNames map[string]string
+	vars       int
+	buf        bytes.Buffer
+}
+
+// generate returns source code of the function converting cfg.TypeName to map and source code of a test
+// comparing the function with Mapper.MapAny.
+func generate(cfg config) (code, testCode []byte, err error) {
+	if cfg.FuncName == "" {
+		cfg.FuncName = cfg.TypeName + "ToMap"
+	}
+
+	naming, ok := namings[cfg.Naming]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown naming %q", cfg.Naming)
+	}
+
+	g := &generator{
+		config:     cfg,
+		naming:     naming,
+		types:      map[string]ast.Expr{},
+		generated:  map[string]bool{},
+		fieldNames: map[string]string{},
+	}
+
+	if err = g.parsePackage(); err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok = g.localStruct(&ast.Ident{Name: cfg.TypeName}); !ok {
+		return nil, nil, fmt.Errorf("struct type %s not found in package %s", cfg.TypeName, g.pkgName)
+	}
+
+	code, err = g.generateCode()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	testCode, err = g.generateTest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return code, testCode, nil
+}
+
+func (g *generator) parsePackage() error {
+	files, err := filepath.Glob(filepath.Join(g.Dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		if strings.HasSuffix(name, "_test.go") || name == g.Output {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		g.pkgName = f.Name.Name
+
+		for _, decl := range f.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if isGeneric(typeSpec) {
+					continue // generic types are not supported
+				}
+
+				g.types[typeSpec.Name.Name] = typeSpec.Type
+			}
+		}
+	}
+
+	if g.pkgName == "" {
+		return fmt.Errorf("no Go files found in %s", g.Dir)
+	}
+
+	return nil
+}
+
+// underlying follows local type names until it finds a type literal, predeclared type or type from another package.
+func (g *generator) underlying(t ast.Expr) ast.Expr {
+	for i := 0; i < len(g.types); i++ {
+		ident, ok := t.(*ast.Ident)
+		if !ok {
+			return t
+		}
+
+		decl, ok := g.types[ident.Name]
+		if !ok {
+			return t
+		}
+
+		t = decl
+	}
+
+	return t
+}
+
+func (g *generator) localStruct(t ast.Expr) (*ast.StructType, bool) {
+	if ident, ok := t.(*ast.Ident); !ok || g.types[ident.Name] == nil {
+		return nil, false
+	}
+
+	structType, ok := g.underlying(t).(*ast.StructType)
+
+	return structType, ok
+}
+
+func (g *generator) stringMap(t ast.Expr) (_ *ast.MapType, plainStringKey bool, ok bool) {
+	mapType, ok := g.underlying(t).(*ast.MapType)
+	if !ok {
+		return nil, false, false
+	}
+
+	key, ok := g.underlying(mapType.Key).(*ast.Ident)
+	if !ok || key.Name != "string" {
+		return nil, false, false
+	}
+
+	plainKey, _ := mapType.Key.(*ast.Ident)
+
+	return mapType, plainKey != nil && plainKey.Name == "string", true
+}
+
+// convertedToMap returns true when MapAny converts value of given type to map[string]interface{}.
+func (g *generator) convertedToMap(t ast.Expr) bool {
+	if _, ok := g.localStruct(t); ok {
+		return true
+	}
+
+	_, _, ok := g.stringMap(t)
+
+	return ok
+}
+
+func (g *generator) helperName(typeName string) string {
+	if typeName == g.TypeName {
+		return g.FuncName
+	}
+
+	return lowerFirst(g.FuncName) + typeName
+}
+
+func (g *generator) requireHelper(typeName string) string {
+	if !g.generated[typeName] {
+		g.generated[typeName] = true
+		g.pending = append(g.pending, typeName)
+	}
+
+	return g.helperName(typeName)
+}
+
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+
+	return prefix + strconv.Itoa(g.vars)
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) generateCode() ([]byte, error) {
+	g.printf("// Code generated by mapifygen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n", g.pkgName)
+
+	g.requireHelper(g.TypeName)
+
+	for len(g.pending) > 0 {
+		typeName := g.pending[0]
+		g.pending = g.pending[1:]
+
+		if err := g.generateStructFunc(typeName); err != nil {
+			return nil, err
+		}
+	}
+
+	return format.Source(g.buf.Bytes())
+}
+
+func (g *generator) generateStructFunc(typeName string) error {
+	structType, _ := g.localStruct(&ast.Ident{Name: typeName})
+
+	g.printf("\n")
+
+	if typeName == g.TypeName {
+		g.printf("// %s converts %s to map the same way as mapify.Mapper.MapAny.\n", g.FuncName, typeName)
+	}
+
+	g.printf("func %s(v %s) map[string]interface{} {\n", g.helperName(typeName), typeName)
+	g.printf("m := map[string]interface{}{}\n")
+
+	for _, field := range structType.Fields.List {
+		names, err := fieldNames(field)
+		if err != nil {
+			return fmt.Errorf("%s: %w", typeName, err)
+		}
+
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+
+			key, omit := g.key(name, field.Tag)
+			if omit {
+				continue
+			}
+
+			if err = g.emitValue(fmt.Sprintf("m[%q]", key), "v."+name, field.Type); err != nil {
+				return fmt.Errorf("%s.%s: %w", typeName, name, err)
+			}
+		}
+	}
+
+	g.printf("return m\n}\n")
+
+	return nil
+}
+
+func fieldNames(field *ast.Field) ([]string, error) {
+	if len(field.Names) > 0 {
+		names := make([]string, len(field.Names))
+		for i, name := range field.Names {
+			names[i] = name.Name
+		}
+
+		return names, nil
+	}
+
+	t := field.Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+
+	switch t := t.(type) {
+	case *ast.Ident:
+		return []string{t.Name}, nil
+	case *ast.SelectorExpr:
+		return []string{t.Sel.Name}, nil
+	default:
+		return nil, fmt.Errorf("unsupported embedded field %T", t)
+	}
+}
+
+// key returns map key for a struct field. omit is true when field should not be included.
+func (g *generator) key(fieldName string, tag *ast.BasicLit) (key string, omit bool) {
+	if g.Tag != "" && tag != nil {
+		unquoted, _ := strconv.Unquote(tag.Value)
+		tagValue := reflect.StructTag(unquoted).Get(g.Tag)
+
+		if tagValue == "-" {
+			return "", true
+		}
+
+		if name := strings.Split(tagValue, ",")[0]; name != "" {
+			return name, false
+		}
+	}
+
+	renamed := g.naming(fieldName)
+	if renamed != fieldName {
+		g.fieldNames[fieldName] = renamed
+	}
+
+	return renamed, false
+}
+
+// emitValue emits statements assigning converted src of type t to target.
+func (g *generator) emitValue(target, src string, t ast.Expr) error {
+	if _, ok := t.(*ast.StructType); ok {
+		return fmt.Errorf("anonymous structs are not supported")
+	}
+
+	if _, ok := g.localStruct(t); ok {
+		g.printf("%s = %s(%s)\n", target, g.requireHelper(t.(*ast.Ident).Name), src)
+
+		return nil
+	}
+
+	switch u := g.underlying(t).(type) {
+	case *ast.StarExpr:
+		if _, ok := u.X.(*ast.StructType); ok {
+			return fmt.Errorf("anonymous structs are not supported")
+		}
+
+		if _, ok := g.localStruct(u.X); ok {
+			g.printf("if %s == nil {\n%s = %s\n} else {\n", src, target, src)
+			g.printf("%s = %s(*%s)\n}\n", target, g.requireHelper(u.X.(*ast.Ident).Name), src)
+
+			return nil
+		}
+	case *ast.ArrayType:
+		if u.Len == nil {
+			return g.emitSlice(target, src, u.Elt)
+		}
+	case *ast.MapType:
+		if mapType, plainKey, ok := g.stringMap(u); ok {
+			return g.emitMap(target, src, mapType, plainKey)
+		}
+
+		if _, ok := g.underlying(u.Key).(*ast.SelectorExpr); ok {
+			return fmt.Errorf("map key of type from another package is not supported")
+		}
+	case *ast.InterfaceType:
+		return fmt.Errorf("interface types are not supported")
+	case *ast.Ident:
+		if u.Name == "error" || u.Name == "any" {
+			return fmt.Errorf("interface types are not supported")
+		}
+	}
+
+	g.printf("%s = %s\n", target, src)
+
+	return nil
+}
+
+func (g *generator) emitSlice(target, src string, elem ast.Expr) error {
+	if g.convertedToMap(elem) {
+		slice, i := g.newVar("s"), g.newVar("i")
+
+		g.printf("{\n%s := make([]map[string]interface{}, len(%s))\n", slice, src)
+		g.printf("for %s := range %s {\n", i, src)
+
+		if err := g.emitMapValue(slice+"["+i+"]", src+"["+i+"]", elem); err != nil {
+			return err
+		}
+
+		g.printf("}\n%s = %s\n}\n", target, slice)
+
+		return nil
+	}
+
+	if inner, ok := g.underlying(elem).(*ast.ArrayType); ok && inner.Len == nil && g.convertedToMap(inner.Elt) {
+		slice, i, innerSlice := g.newVar("s"), g.newVar("i"), g.newVar("s")
+
+		g.printf("{\nvar %s [][]map[string]interface{}\n", slice)
+		g.printf("for %s := range %s {\n", i, src)
+		g.printf("var %s []map[string]interface{}\n", innerSlice)
+
+		if err := g.emitSlice(innerSlice, src+"["+i+"]", inner.Elt); err != nil {
+			return err
+		}
+
+		g.printf("%s = append(%s, %s)\n}\n", slice, slice, innerSlice)
+		g.printf("%s = %s\n}\n", target, slice)
+
+		return nil
+	}
+
+	g.printf("%s = %s\n", target, src)
+
+	return nil
+}
+
+func (g *generator) emitMapValue(target, src string, t ast.Expr) error {
+	if _, ok := g.localStruct(t); ok {
+		g.printf("%s = %s(%s)\n", target, g.requireHelper(t.(*ast.Ident).Name), src)
+
+		return nil
+	}
+
+	mapType, plainKey, _ := g.stringMap(t)
+
+	return g.emitMap(target, src, mapType, plainKey)
+}
+
+func (g *generator) emitMap(target, src string, mapType *ast.MapType, plainStringKey bool) error {
+	result, k, e := g.newVar("m"), g.newVar("k"), g.newVar("e")
+
+	key := k
+	if !plainStringKey {
+		key = "string(" + k + ")"
+	}
+
+	g.printf("{\n%s := make(map[string]interface{}, len(%s))\n", result, src)
+	g.printf("for %s, %s := range %s {\n", k, e, src)
+
+	if err := g.emitValue(result+"["+key+"]", e, mapType.Value); err != nil {
+		return err
+	}
+
+	g.printf("}\n%s = %s\n}\n", target, result)
+
+	return nil
+}
+
+func (g *generator) generateTest() ([]byte, error) {
+	g.buf.Reset()
+
+	g.printf("// Code generated by mapifygen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkgName)
+	g.printf("import (\n")
+
+	g.printf("%q\n", "math/rand")
+
+	g.printf("%q\n", "reflect")
+
+	if g.Tag != "" {
+		g.printf("%q\n", "strings")
+	}
+
+	g.printf("%q\n", "testing")
+
+	g.printf("%q\n", "testing/quick")
+
+	g.printf("\n%q\n)\n", "github.com/elgopher/mapify")
+
+	randomValue := lowerFirst(g.FuncName) + "RandomValue"
+
+	g.printf("\nfunc Test%s(t *testing.T) {\n", g.FuncName)
+	g.printf("var zero %s\n", g.TypeName)
+	g.printf("pkgPath := reflect.TypeOf(zero).PkgPath()\n")
+
+	if len(g.fieldNames) > 0 {
+		g.printf("names := map[string]string{\n")
+
+		fieldNames := make([]string, 0, len(g.fieldNames))
+		for name := range g.fieldNames {
+			fieldNames = append(fieldNames, name)
+		}
+
+		sort.Strings(fieldNames)
+
+		for _, name := range fieldNames {
+			g.printf("%q: %q,\n", name, g.fieldNames[name])
+		}
+
+		g.printf("}\n")
+	}
+
+	g.printf("mapper := mapify.Mapper{\n")
+	g.printf(`ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+		for t := value.Type(); ; t = t.Elem() {
+			if t.PkgPath() != "" && t.PkgPath() != pkgPath {
+				return false, nil
+			}
+
+			if t.Kind() != reflect.Ptr && t.Kind() != reflect.Slice {
+				return true, nil
+			}
+		}
+	},
+`)
+
+	if g.Tag != "" {
+		g.printf(`Filter: func(path string, e mapify.Element) (bool, error) {
+		field, ok := e.StructField()
+
+		return !ok || field.Tag.Get(%q) != "-", nil
+	},
+`, g.Tag)
+	}
+
+	if g.Tag != "" || len(g.fieldNames) > 0 {
+		g.printf(`Rename: func(path string, e mapify.Element) (string, error) {
+		field, ok := e.StructField()
+		if !ok {
+			return e.Name(), nil
+		}
+`)
+
+		if g.Tag != "" {
+			g.printf(`
+		if name := strings.Split(field.Tag.Get(%q), ",")[0]; name != "" {
+			return name, nil
+		}
+`, g.Tag)
+		}
+
+		if len(g.fieldNames) > 0 {
+			g.printf(`
+		if name, ok := names[field.Name]; ok {
+			return name, nil
+		}
+`)
+		}
+
+		g.printf("\nreturn e.Name(), nil\n},\n")
+	}
+
+	g.printf("}\n\n")
+	g.printf("values := []%s{zero}\n", g.TypeName)
+
+	g.printf(`
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		values = append(values, %s(reflect.TypeOf(zero), rnd, 0).Interface().(%s))
+	}
+`, randomValue, g.TypeName)
+
+	g.printf(`
+	for _, v := range values {
+		expected, err := mapper.MapAny(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := %s(v); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("%s(%%+v) returned %%+v, but MapAny returned %%+v", v, actual, expected)
+		}
+	}
+}
+`, g.FuncName, g.FuncName)
+
+	// quick.Value cannot be used directly, because it panics for structs with unexported fields, such as time.Time
+	g.printf(`
+func %s(t reflect.Type, rnd *rand.Rand, depth int) reflect.Value {
+	v := reflect.New(t).Elem()
+	if depth > 4 {
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				v.Field(i).Set(%s(t.Field(i).Type, rnd, depth+1))
+			}
+		}
+	case reflect.Ptr:
+		if rnd.Intn(4) > 0 {
+			ptr := reflect.New(t.Elem())
+			ptr.Elem().Set(%s(t.Elem(), rnd, depth+1))
+			v.Set(ptr)
+		}
+	case reflect.Slice:
+		if rnd.Intn(4) > 0 {
+			n := rnd.Intn(3)
+			v.Set(reflect.MakeSlice(t, n, n))
+			for i := 0; i < n; i++ {
+				v.Index(i).Set(%s(t.Elem(), rnd, depth+1))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(%s(t.Elem(), rnd, depth+1))
+		}
+	case reflect.Map:
+		if rnd.Intn(4) > 0 {
+			v.Set(reflect.MakeMap(t))
+			for i := rnd.Intn(3); i > 0; i-- {
+				v.SetMapIndex(%s(t.Key(), rnd, depth+1), %s(t.Elem(), rnd, depth+1))
+			}
+		}
+	case reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+	default:
+		if random, ok := quick.Value(t, rnd); ok {
+			v.Set(random)
+		}
+	}
+
+	return v
+}
+`, randomValue, randomValue, randomValue, randomValue, randomValue, randomValue, randomValue)
+
+	return format.Source(g.buf.Bytes())
+}
+
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToLower(runes[0])
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,110 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("should generate function returning the same output as MapAny", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("runs go test on generated code")
+		}
+
+		tests := map[string]config{
+			"default":                   {TypeName: "Order"},
+			"json tag and snake case":   {TypeName: "Order", Tag: "json", Naming: "snake"},
+			"camel case":                {TypeName: "Order", Naming: "camel", FuncName: "OrderMap"},
+			"struct with private field": {TypeName: "Payment", Tag: "json", Naming: "lower"},
+		}
+
+		for name, cfg := range tests {
+			t.Run(name, func(t *testing.T) {
+				dir := copyPackage(t, "testdata/orders")
+				cfg.Dir = dir
+				cfg.Output = "generated.go"
+				if cfg.Naming == "" {
+					cfg.Naming = "none"
+				}
+				// when
+				err := run(cfg, true)
+				// then
+				require.NoError(t, err)
+				output, err := exec.Command("go", "test", "./"+dir).CombinedOutput()
+				assert.NoError(t, err, string(output))
+			})
+		}
+	})
+
+	t.Run("should return error", func(t *testing.T) {
+		tests := map[string]config{
+			"missing type":    {TypeName: "Missing", Naming: "none"},
+			"not a struct":    {TypeName: "Status", Naming: "none"},
+			"unknown naming":  {TypeName: "Order", Naming: "unknown"},
+			"interface field": {TypeName: "Event", Naming: "none"},
+		}
+
+		for name, cfg := range tests {
+			t.Run(name, func(t *testing.T) {
+				cfg.Dir = "testdata/orders"
+				if name == "interface field" {
+					cfg.Dir = "testdata/events"
+				}
+				// when
+				_, _, err := generate(cfg)
+				// then
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func copyPackage(t *testing.T, src string) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("testdata", "generated")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	files, err := filepath.Glob(filepath.Join(src, "*.go"))
+	require.NoError(t, err)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(dir, filepath.Base(file)), content, 0o644)
+		require.NoError(t, err)
+	}
+
+	return dir
+}
+
+func TestNaming(t *testing.T) {
+	tests := map[string]struct {
+		snake, camel string
+	}{
+		"Field":        {snake: "field", camel: "field"},
+		"CreatedAt":    {snake: "created_at", camel: "createdAt"},
+		"ID":           {snake: "id", camel: "id"},
+		"UserID":       {snake: "user_id", camel: "userID"},
+		"HTTPServer":   {snake: "http_server", camel: "httpServer"},
+		"Address2City": {snake: "address2_city", camel: "address2City"},
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected.snake, snakeCase(name))
+			assert.Equal(t, expected.camel, camelCase(name))
+		})
+	}
+}
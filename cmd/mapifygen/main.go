@@ -0,0 +1,73 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+// Command mapifygen generates reflection-free functions converting a struct into map[string]interface{}.
+//
+// Generated function returns the same output as mapify.Mapper.MapAny would, when Mapper is configured to:
+//
+//   - omit fields with tag value "-" (when -tag is given),
+//   - rename fields using tag name or naming strategy (-naming),
+//   - convert only structs declared in the same package (structs from other packages, such as time.Time,
+//     are copied as-is).
+//
+// Usage:
+//
+//	//go:generate go run github.com/elgopher/mapify/cmd/mapifygen -type Order -tag json -naming snake -test
+//
+// Fields of interface type are not supported, because their dynamic value is not known during generation.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	cfg := config{Dir: "."}
+
+	flag.StringVar(&cfg.TypeName, "type", "", "name of the struct type (required)")
+	flag.StringVar(&cfg.FuncName, "func", "", "name of generated function (default <type>ToMap)")
+	flag.StringVar(&cfg.Tag, "tag", "", "struct tag used to rename and omit fields, for example json")
+	flag.StringVar(&cfg.Naming, "naming", "none", "naming of fields without tag name: none, snake, camel or lower")
+	output := flag.String("output", "", "output file name (default <type>_mapify.go)")
+	test := flag.Bool("test", false, "generate test comparing generated function with Mapper.MapAny")
+	flag.Parse()
+
+	if cfg.TypeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *output == "" {
+		*output = strings.ToLower(cfg.TypeName) + "_mapify.go"
+	}
+
+	cfg.Output = *output
+
+	if err := run(cfg, *test); err != nil {
+		fmt.Fprintln(os.Stderr, "mapifygen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg config, test bool) error {
+	code, testCode, err := generate(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Join(cfg.Dir, cfg.Output), code, 0o644); err != nil {
+		return err
+	}
+
+	if !test {
+		return nil
+	}
+
+	testOutput := strings.TrimSuffix(cfg.Output, ".go") + "_test.go"
+
+	return os.WriteFile(filepath.Join(cfg.Dir, testOutput), testCode, 0o644)
+}
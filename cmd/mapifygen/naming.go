@@ -0,0 +1,32 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package main
+
+import (
+	"strings"
+
+	"github.com/elgopher/mapify/internal/naming"
+)
+
+var namings = map[string]func(string) string{
+	"none":  func(s string) string { return s },
+	"snake": snakeCase,
+	"camel": camelCase,
+	"lower": strings.ToLower,
+}
+
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(naming.Words(s), "_"))
+}
+
+func camelCase(s string) string {
+	w := naming.Words(s)
+	if len(w) == 0 {
+		return s
+	}
+
+	w[0] = strings.ToLower(w[0])
+
+	return strings.Join(w, "")
+}
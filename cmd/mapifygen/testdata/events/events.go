@@ -0,0 +1,5 @@
+package events
+
+type Event struct {
+	Payload interface{}
+}
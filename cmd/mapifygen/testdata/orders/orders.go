@@ -0,0 +1,46 @@
+package orders
+
+import "time"
+
+type Order struct {
+	ID         int `json:"id"`
+	CustomerID string
+	Status     Status
+	Customer   *Customer
+	Items      []Item
+	Groups     [][]Item
+	Labels     map[string]string
+	ByName     map[Status]Item
+	Created    time.Time
+	Times      []time.Time
+	Secret     string `json:"-"`
+	Pointers   []*Item
+	Array      [2]Item
+	Raw        map[int]string
+	Address
+}
+
+type Status string
+
+type Customer struct {
+	Name    string
+	Parent  *Customer
+	Friends Customers
+}
+
+type Customers []Customer
+
+type Item struct {
+	Price float64 `json:"price,omitempty"`
+	Tags  []map[string]Item
+}
+
+type Address struct {
+	City string
+}
+
+type Payment struct {
+	Amount   int `json:"amount"`
+	Customer Customer
+	token    string
+}
@@ -0,0 +1,13 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+//go:build go1.18
+
+package main
+
+import "go/ast"
+
+// isGeneric returns true for types with type parameters.
+func isGeneric(typeSpec *ast.TypeSpec) bool {
+	return typeSpec.TypeParams != nil
+}
@@ -0,0 +1,13 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+//go:build !go1.18
+
+package main
+
+import "go/ast"
+
+// isGeneric returns false, because type parameters cannot be parsed before Go 1.18.
+func isGeneric(*ast.TypeSpec) bool {
+	return false
+}
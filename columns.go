@@ -0,0 +1,299 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType   = reflect.TypeOf(time.Time{})
+	mapType    = reflect.TypeOf(map[string]interface{}{})
+)
+
+// ColumnsOptions configures MapColumns.
+type ColumnsOptions struct {
+	// Separator joins names of nested struct and its fields, for example "address_city". Default is "_".
+	Separator string
+	// SkipNil omits columns with nil values (nil pointers, interfaces, slices and maps). Useful for partial updates.
+	SkipNil bool
+}
+
+// Columns are ordered column names and values, for example for INSERT or UPDATE statements.
+// Values[i] is the value of column Names[i].
+type Columns struct {
+	Names  []string
+	Values []interface{}
+}
+
+// MapColumns maps a struct (or map) using MapAny into columns. Columns are ordered by declaration of struct fields,
+// keys of maps are sorted. Nested structs are flattened, so each nested field becomes a column prefixed with
+// the name of the struct. Nested maps are not flattened, because their keys depend on data, so each map is
+// a single column value (for example for JSON column).
+//
+// Values implementing driver.Valuer (such as sql.NullString) and time.Time are never converted, so they are passed
+// as-is to database driver. For other values ShouldConvert is run as usual. Nil pointer to nested struct gives
+// the same columns as non-nil one, all with nil values, so columns do not depend on data.
+func (i Mapper) MapColumns(v interface{}, options ColumnsOptions) (Columns, error) {
+	if options.Separator == "" {
+		options.Separator = "_"
+	}
+
+	order := newFieldsOrder()
+	i.elementAdded = order.add
+
+	shouldConvert := i.ShouldConvert
+	i.ShouldConvert = func(path string, value reflect.Value) (bool, error) {
+		if isColumnValue(value.Type()) || value.Type() == mapColumnType {
+			return false, nil
+		}
+
+		if shouldConvert == nil {
+			return true, nil
+		}
+
+		return shouldConvert(path, value)
+	}
+
+	instance := i.newInstance()
+	mapValue := instance.MapValue
+
+	i.MapValue = func(path string, e Element) (interface{}, error) {
+		mapped, err := mapValue(path, e)
+		if err != nil {
+			return nil, err
+		}
+
+		if value := reflect.ValueOf(mapped); value.Kind() == reflect.Map {
+			return mapColumn{value: mapped}, nil
+		}
+
+		if options.SkipNil {
+			return mapped, nil
+		}
+
+		return instance.expandNilStruct(path, mapped, options)
+	}
+
+	mapped, err := i.MapAny(v)
+	if err != nil {
+		return Columns{}, err
+	}
+
+	mappedMap, ok := mapped.(map[string]interface{})
+	if !ok {
+		return Columns{}, fmt.Errorf("%T was not converted to a map", v)
+	}
+
+	var columns Columns
+	columns.add("", "", reflect.ValueOf(mappedMap), order, options)
+
+	return columns, nil
+}
+
+// isColumnValue returns true for types of values passed as-is to database driver.
+func isColumnValue(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == timeType || reflect.PtrTo(t).Implements(valuerType)
+}
+
+// mapColumn is a nested map, which is a single column value, because its keys depend on data.
+type mapColumn struct {
+	value interface{}
+}
+
+var mapColumnType = reflect.TypeOf(mapColumn{})
+
+// nilColumns are names of columns of nil pointer to nested struct, relative to the name of the struct.
+type nilColumns []string
+
+// expandNilStruct replaces nil pointer to nested struct with nilColumns.
+func (i Mapper) expandNilStruct(path string, v interface{}, options ColumnsOptions) (interface{}, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || !value.IsNil() {
+		return v, nil
+	}
+
+	structType, nested, err := i.nestedStruct(path, value)
+	if err != nil || !nested {
+		return v, err
+	}
+
+	columns, err := i.structColumns(structType, path, options)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(nilColumns, len(columns))
+	for j, column := range columns {
+		names[j] = column.name
+	}
+
+	return names, nil
+}
+
+// structColumn is a column storing a field of struct or nested struct.
+type structColumn struct {
+	name  string
+	index []int // as in reflect.Value.FieldByIndex
+}
+
+// structColumns returns columns of all fields of structType in declaration order. The column name of each field
+// is computed by Filter and Rename (run with zero value of the field), and fields of nested structs are prefixed
+// with the name of the struct and options.Separator. Nested structs which types are already being expanded are
+// skipped, so self-referencing types have finite columns.
+func (i Mapper) structColumns(structType reflect.Type, path string, options ColumnsOptions) ([]structColumn, error) {
+	return i.appendStructColumns(nil, structType, path, "", nil, options, nil)
+}
+
+func (i Mapper) appendStructColumns(columns []structColumn, structType reflect.Type, path, prefix string,
+	index []int, options ColumnsOptions, parents []reflect.Type) ([]structColumn, error) {
+
+	value := reflect.New(structType).Elem()
+	parents = append(parents, structType)
+
+	for j := 0; j < structType.NumField(); j++ {
+		field := structType.Field(j)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		element, err := i.prepare(fieldPath, Element{name: field.Name, Value: value.Field(j), field: &field})
+		if err != nil {
+			return nil, err
+		}
+
+		accepted, err := i.Filter(fieldPath, element)
+		if err != nil {
+			return nil, fmt.Errorf("Filter failed: %w", err)
+		}
+
+		if !accepted {
+			continue
+		}
+
+		name, err := i.Rename(fieldPath, element)
+		if err != nil {
+			return nil, fmt.Errorf("Rename failed: %w", err)
+		}
+
+		if prefix != "" {
+			name = prefix + options.Separator + name
+		}
+
+		fieldIndex := append(append([]int{}, index...), j)
+
+		nestedType, nested, err := i.nestedStruct(fieldPath, value.Field(j))
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !nested:
+			columns = append(columns, structColumn{name: name, index: fieldIndex})
+		case !containsType(parents, nestedType):
+			columns, err = i.appendStructColumns(columns, nestedType, fieldPath, name, fieldIndex, options, parents)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return columns, nil
+}
+
+// nestedStruct returns type of struct (or pointer to struct) which fields are stored in separate columns.
+func (i Mapper) nestedStruct(path string, value reflect.Value) (_ reflect.Type, nested bool, _ error) {
+	t := value.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		value = reflect.New(t) // ShouldConvert receives pointer to zero struct, like for non-nil pointers
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, false, nil
+	}
+
+	shouldConvert, err := i.ShouldConvert(path, value)
+	if err != nil {
+		return nil, false, fmt.Errorf("ShouldConvert failed: %w", err)
+	}
+
+	return t, shouldConvert, nil
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add adds columns of value found at path.
+func (c *Columns) add(name, path string, value reflect.Value, order *fieldsOrder, options ColumnsOptions) {
+	value = dereferenceInterface(value)
+
+	if columns, ok := interfaceOrNil(value).(nilColumns); ok {
+		for _, column := range columns {
+			c.Names = append(c.Names, name+options.Separator+column)
+			c.Values = append(c.Values, nil)
+		}
+
+		return
+	}
+
+	if value.IsValid() && value.Type() == mapType { // converted struct or map
+		for _, key := range order.sortedKeys(path, value) {
+			column := key
+			if name != "" {
+				column = name + options.Separator + key
+			}
+
+			c.add(column, order.path(path, key), value.MapIndex(reflect.ValueOf(key)), order, options)
+		}
+
+		return
+	}
+
+	if column, ok := interfaceOrNil(value).(mapColumn); ok {
+		value = reflect.ValueOf(column.value)
+	}
+
+	if !value.IsValid() {
+		if !options.SkipNil {
+			c.Names = append(c.Names, name)
+			c.Values = append(c.Values, nil)
+		}
+
+		return
+	}
+
+	if options.SkipNil && isNil(value) {
+		return
+	}
+
+	c.Names = append(c.Names, name)
+	c.Values = append(c.Values, value.Interface())
+}
+
+func isNil(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,161 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID        int
+	Name      sql.NullString
+	Email     *string
+	Address   address
+	CreatedAt time.Time
+	Data      []byte
+	Settings  map[string]string
+}
+
+type address struct {
+	City   string
+	Street *string
+}
+
+func TestMapper_MapColumns(t *testing.T) {
+	createdAt := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	value := user{
+		ID:        1,
+		Name:      sql.NullString{String: "name", Valid: true},
+		Address:   address{City: "city"},
+		CreatedAt: createdAt,
+		Data:      []byte("data"),
+	}
+
+	t.Run("should map struct into ordered columns", func(t *testing.T) {
+		actual, err := mapify.Mapper{}.MapColumns(value, mapify.ColumnsOptions{})
+		require.NoError(t, err)
+		expected := mapify.Columns{
+			Names: []string{
+				"ID", "Name", "Email", "Address_City", "Address_Street", "CreatedAt", "Data", "Settings",
+			},
+			Values: []interface{}{
+				1, value.Name, (*string)(nil), "city", (*string)(nil), createdAt, []byte("data"),
+				map[string]string(nil),
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should skip nil values", func(t *testing.T) {
+		actual, err := mapify.Mapper{}.MapColumns(value, mapify.ColumnsOptions{SkipNil: true, Separator: "."})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ID", "Name", "Address.City", "CreatedAt", "Data"}, actual.Names)
+	})
+
+	t.Run("should expand nil pointer to nested struct", func(t *testing.T) {
+		type order struct {
+			ID      int
+			Billing *address
+			PaidAt  *time.Time
+		}
+
+		street := "street"
+		filled, err := mapify.Mapper{}.MapColumns(
+			order{Billing: &address{City: "city", Street: &street}, PaidAt: &createdAt}, mapify.ColumnsOptions{})
+		require.NoError(t, err)
+		// when
+		actual, err := mapify.Mapper{}.MapColumns(order{ID: 1}, mapify.ColumnsOptions{})
+		// then
+		require.NoError(t, err)
+		expected := mapify.Columns{
+			Names:  []string{"ID", "Billing_City", "Billing_Street", "PaidAt"},
+			Values: []interface{}{1, nil, nil, (*time.Time)(nil)},
+		}
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, filled.Names, actual.Names)
+	})
+
+	t.Run("should skip nil pointer to nested struct", func(t *testing.T) {
+		type order struct {
+			ID      int
+			Billing *address
+		}
+		// when
+		actual, err := mapify.Mapper{}.MapColumns(order{ID: 1}, mapify.ColumnsOptions{SkipNil: true})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ID"}, actual.Names)
+	})
+
+	t.Run("should expand nil pointer to self-referencing struct once", func(t *testing.T) {
+		type node struct {
+			Name string
+			Next *node
+		}
+		// when
+		actual, err := mapify.Mapper{}.MapColumns(node{Name: "a"}, mapify.ColumnsOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Next_Name"}, actual.Names)
+	})
+
+	t.Run("should use Filter, Rename and ShouldConvert", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+				return path != ".Settings", nil
+			},
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return path == ".ID" || path == ".Settings", nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return strings.ToLower(e.Name()), nil
+			},
+		}
+		v := value
+		v.Settings = map[string]string{"k": "v"}
+		// when
+		actual, err := mapper.MapColumns(v, mapify.ColumnsOptions{})
+		// then
+		require.NoError(t, err)
+		expected := mapify.Columns{
+			Names:  []string{"id", "settings"},
+			Values: []interface{}{1, map[string]string{"k": "v"}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should sort keys of maps", func(t *testing.T) {
+		actual, err := mapify.Mapper{}.MapColumns(map[string]int{"b": 2, "a": 1}, mapify.ColumnsOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, mapify.Columns{Names: []string{"a", "b"}, Values: []interface{}{1, 2}}, actual)
+	})
+
+	t.Run("should not flatten nested maps", func(t *testing.T) {
+		v := map[string]interface{}{"id": 1, "data": map[string]interface{}{"key": "value"}}
+		// when
+		actual, err := mapify.Mapper{}.MapColumns(v, mapify.ColumnsOptions{})
+		// then
+		require.NoError(t, err)
+		expected := mapify.Columns{
+			Names:  []string{"data", "id"},
+			Values: []interface{}{map[string]interface{}{"key": "value"}, 1},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return error when value is not converted to map", func(t *testing.T) {
+		for _, v := range []interface{}{[]user{}, nil} {
+			_, err := mapify.Mapper{}.MapColumns(v, mapify.ColumnsOptions{})
+			assert.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeStrategy specifies how DeepMerge merges values found under the same path. Maps are always merged
+// recursively, so strategies are used for other values, such as slices and scalars.
+type MergeStrategy int
+
+const (
+	// MergeOverride replaces earlier values with later ones.
+	MergeOverride MergeStrategy = iota
+	// MergeKeepFirst keeps the first value.
+	MergeKeepFirst
+	// MergeAppend appends elements of later slices to earlier ones. Other values are overridden.
+	MergeAppend
+	// MergeUnionByKey merges slices of maps by key field (MergeRule.Key): elements with the same key are merged
+	// recursively and other elements are appended. Elements use the Default strategy, unless rules match their
+	// paths. Other values are overridden.
+	MergeUnionByKey
+	// MergeErrorOnConflict returns error when values are different.
+	MergeErrorOnConflict
+)
+
+// MergeRule sets strategy for paths matching the pattern.
+type MergeRule struct {
+	// Path is a pattern in MapAny path syntax with keys of merged maps, for example ".servers". "*" matches any
+	// key, "[*]" matches any index and "**" matches any number of segments, for example ".**.tags".
+	Path     string
+	Strategy MergeStrategy
+	// Key is the name of key field used by MergeUnionByKey.
+	Key string
+}
+
+// MergeOptions configures DeepMerge.
+type MergeOptions struct {
+	// Rules are checked in order and the first rule matching the path is used. Paths not matching any rule
+	// use the strategy of the parent map, and the root uses Default.
+	Rules   []MergeRule
+	Default MergeStrategy
+	// SkipNil ignores nil values (including nil pointers, slices and maps) in later maps, so they do not
+	// override earlier values.
+	SkipNil bool
+}
+
+// DeepMerge maps values using MapAny and merges them using function DeepMerge. All values must be converted
+// to maps.
+func (i Mapper) DeepMerge(values []interface{}, options MergeOptions) (map[string]interface{}, error) {
+	maps := make([]map[string]interface{}, len(values))
+
+	for j, v := range values {
+		var err error
+
+		maps[j], err = i.mapToMap(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return DeepMerge(maps, options)
+}
+
+// DeepMerge merges maps in order, for example defaults, configuration file and environment variables. Maps with
+// string keys are merged recursively, and other values are merged using strategies selected by options.
+// Maps with string keys and slices are copied into map[string]interface{} and []interface{}, so none
+// of the merged maps is modified.
+//
+// Error is returned when a rule is invalid, for conflicts found by MergeErrorOnConflict and when slice element
+// merged by MergeUnionByKey has no key.
+func DeepMerge(maps []map[string]interface{}, options MergeOptions) (map[string]interface{}, error) {
+	m := merger{options: options}
+
+	for _, rule := range options.Rules {
+		pattern, err := parsePathPattern(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if rule.Strategy == MergeUnionByKey && rule.Key == "" {
+			return nil, fmt.Errorf("rule for %s has no key", rule.Path)
+		}
+
+		m.patterns = append(m.patterns, pattern)
+	}
+
+	result := map[string]interface{}{}
+
+	for _, next := range maps {
+		merged, err := m.mergeMaps("", result, reflect.ValueOf(next), m.rule("", MergeRule{Strategy: options.Default}))
+		if err != nil {
+			return nil, err
+		}
+
+		result = merged
+	}
+
+	return result, nil
+}
+
+type merger struct {
+	options  MergeOptions
+	patterns []pathPattern
+}
+
+// rule returns the first rule matching the path, or inherited rule.
+func (m merger) rule(path string, inherited MergeRule) MergeRule {
+	for j, pattern := range m.patterns {
+		if pattern.match(path) {
+			return m.options.Rules[j]
+		}
+	}
+
+	return inherited
+}
+
+func (m merger) mergeMaps(path string, result map[string]interface{}, next reflect.Value, rule MergeRule) (
+	map[string]interface{}, error) {
+
+	iter := next.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		keyPath := path + "." + key
+
+		value := dereferenceInterface(iter.Value())
+		if m.options.SkipNil && isNull(value) {
+			continue
+		}
+
+		current, exists := result[key]
+		if !exists {
+			result[key] = copyContainers(value)
+			continue
+		}
+
+		merged, err := m.mergeValue(keyPath, current, value, m.rule(keyPath, rule))
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = merged
+	}
+
+	return result, nil
+}
+
+// mergeValue merges next into current, which is a copy owned by the merger.
+func (m merger) mergeValue(path string, current interface{}, next reflect.Value, rule MergeRule) (
+	interface{}, error) {
+
+	next = dereferenceInterface(next)
+
+	if currentMap, ok := current.(map[string]interface{}); ok && isStringMap(next) && !next.IsNil() {
+		return m.mergeMaps(path, currentMap, next, rule)
+	}
+
+	currentSlice, isCurrentSlice := current.([]interface{})
+	bothSlices := isCurrentSlice && isSlice(next) && !isNull(next)
+
+	switch {
+	case rule.Strategy == MergeKeepFirst:
+		return current, nil
+	case rule.Strategy == MergeErrorOnConflict:
+		if !reflect.DeepEqual(current, copyContainers(next)) {
+			return nil, fmt.Errorf("conflicting values at %s: %v and %v", path, current, next)
+		}
+
+		return current, nil
+	case rule.Strategy == MergeAppend && bothSlices:
+		return append(currentSlice, copyContainers(next).([]interface{})...), nil
+	case rule.Strategy == MergeUnionByKey && bothSlices:
+		return m.unionByKey(path, currentSlice, next, rule)
+	default:
+		return copyContainers(next), nil
+	}
+}
+
+func (m merger) unionByKey(path string, current []interface{}, next reflect.Value, rule MergeRule) (
+	[]interface{}, error) {
+
+	indexes := map[interface{}]int{}
+
+	for j, element := range current {
+		key, err := elementKey(reflect.ValueOf(element), rule.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", slicePath(path, j), err)
+		}
+
+		indexes[key] = j
+	}
+
+	for j := 0; j < next.Len(); j++ {
+		key, err := elementKey(next.Index(j), rule.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", slicePath(path, j), err)
+		}
+
+		index, ok := indexes[key]
+		if !ok {
+			indexes[key] = len(current)
+			current = append(current, copyContainers(next.Index(j)))
+
+			continue
+		}
+
+		elementPath := slicePath(path, index)
+
+		rule := m.rule(elementPath, MergeRule{Strategy: m.options.Default})
+
+		merged, err := m.mergeValue(elementPath, current[index], next.Index(j), rule)
+		if err != nil {
+			return nil, err
+		}
+
+		current[index] = merged
+	}
+
+	return current, nil
+}
@@ -0,0 +1,243 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepMerge(t *testing.T) {
+	t.Run("should merge nested maps overriding values", func(t *testing.T) {
+		defaults := decodeObject(t, `{"db":{"host":"localhost","port":5432},"tags":["a"]}`)
+		file := decodeObject(t, `{"db":{"host":"db.local"},"tags":["b"],"debug":true}`)
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{defaults, file}, mapify.MergeOptions{})
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"db":{"host":"db.local","port":5432},"tags":["b"],"debug":true}`, merged)
+		assert.Equal(t, decodeObject(t, `{"db":{"host":"localhost","port":5432},"tags":["a"]}`), defaults,
+			"maps must not be modified")
+	})
+
+	t.Run("should return empty map when there are no maps", func(t *testing.T) {
+		merged, err := mapify.DeepMerge(nil, mapify.MergeOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{}, merged)
+	})
+
+	t.Run("should override with nil", func(t *testing.T) {
+		first := decodeObject(t, `{"a":1}`)
+		second := decodeObject(t, `{"a":null}`)
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{first, second}, mapify.MergeOptions{})
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"a":null}`, merged)
+	})
+
+	t.Run("should skip nil values", func(t *testing.T) {
+		var nilPointer *string
+
+		first := map[string]interface{}{"a": 1, "b": []string{"x"}, "c": 2}
+		second := map[string]interface{}{"a": nilPointer, "b": []string(nil), "c": nil, "d": nil}
+		options := mapify.MergeOptions{SkipNil: true}
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"a":1,"b":["x"],"c":2}`, merged)
+	})
+
+	t.Run("should use default strategy", func(t *testing.T) {
+		first := decodeObject(t, `{"a":1,"nested":{"b":2}}`)
+		second := decodeObject(t, `{"a":3,"nested":{"b":4,"c":5}}`)
+		options := mapify.MergeOptions{Default: mapify.MergeKeepFirst}
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"a":1,"nested":{"b":2,"c":5}}`, merged)
+	})
+
+	t.Run("should append slices", func(t *testing.T) {
+		first := decodeObject(t, `{"tags":["a"],"nested":{"tags":["x"]},"other":["o"]}`)
+		second := decodeObject(t, `{"tags":["b","c"],"nested":{"tags":["y"]},"other":["p"]}`)
+		options := mapify.MergeOptions{
+			Rules: []mapify.MergeRule{
+				{Path: ".**.tags", Strategy: mapify.MergeAppend},
+			},
+		}
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"tags":["a","b","c"],"nested":{"tags":["x","y"]},"other":["p"]}`, merged)
+	})
+
+	t.Run("should override non-slice value when appending", func(t *testing.T) {
+		first := decodeObject(t, `{"tags":["a"]}`)
+		second := decodeObject(t, `{"tags":"b"}`)
+		options := mapify.MergeOptions{Default: mapify.MergeAppend}
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"tags":"b"}`, merged)
+	})
+
+	t.Run("should union slices by key", func(t *testing.T) {
+		first := decodeObject(t, `{"servers":[{"name":"a","port":1,"tags":["x"]},{"name":"b","port":2}]}`)
+		second := decodeObject(t, `{"servers":[{"name":"b","port":3},{"name":"c","port":4}]}`)
+		third := decodeObject(t, `{"servers":[{"name":"a","tags":["y"]}]}`)
+		options := mapify.MergeOptions{
+			Rules: []mapify.MergeRule{
+				{Path: ".servers[*].tags", Strategy: mapify.MergeAppend},
+				{Path: ".servers", Strategy: mapify.MergeUnionByKey, Key: "name"},
+			},
+		}
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{first, second, third}, options)
+		// then
+		require.NoError(t, err)
+		assertJSON(t,
+			`{"servers":[{"name":"a","port":1,"tags":["x","y"]},{"name":"b","port":3},{"name":"c","port":4}]}`,
+			merged)
+	})
+
+	t.Run("should merge elements with the same key using default strategy", func(t *testing.T) {
+		first := decodeObject(t, `{"servers":[{"name":"a","port":1}]}`)
+		second := decodeObject(t, `{"servers":[{"name":"a","port":2,"debug":true}]}`)
+		rules := []mapify.MergeRule{
+			{Path: ".servers", Strategy: mapify.MergeUnionByKey, Key: "name"},
+		}
+
+		t.Run("keep first", func(t *testing.T) {
+			options := mapify.MergeOptions{Rules: rules, Default: mapify.MergeKeepFirst}
+			// when
+			merged, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+			// then
+			require.NoError(t, err)
+			assertJSON(t, `{"servers":[{"name":"a","port":1,"debug":true}]}`, merged)
+		})
+
+		t.Run("error on conflict", func(t *testing.T) {
+			options := mapify.MergeOptions{Rules: rules, Default: mapify.MergeErrorOnConflict}
+			// when
+			_, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+			// then
+			assert.ErrorContains(t, err, ".servers[0].port")
+		})
+	})
+
+	t.Run("should return error when element has no key", func(t *testing.T) {
+		first := decodeObject(t, `{"servers":[{"name":"a"}]}`)
+		second := decodeObject(t, `{"servers":[{"port":1}]}`)
+		options := mapify.MergeOptions{
+			Rules: []mapify.MergeRule{
+				{Path: ".servers", Strategy: mapify.MergeUnionByKey, Key: "name"},
+			},
+		}
+		// when
+		_, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		assert.ErrorContains(t, err, ".servers[0]")
+	})
+
+	t.Run("should return error on conflict", func(t *testing.T) {
+		first := decodeObject(t, `{"db":{"host":"a","port":1}}`)
+		second := decodeObject(t, `{"db":{"host":"b","port":1}}`)
+		options := mapify.MergeOptions{
+			Rules: []mapify.MergeRule{
+				{Path: ".db", Strategy: mapify.MergeErrorOnConflict},
+			},
+		}
+		// when
+		_, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		assert.ErrorContains(t, err, ".db.host")
+	})
+
+	t.Run("should not return error when values are equal", func(t *testing.T) {
+		first := decodeObject(t, `{"db":{"host":"a"},"tags":["x"]}`)
+		second := map[string]interface{}{"db": map[string]string{"host": "a"}, "tags": []string{"x"}}
+		options := mapify.MergeOptions{Default: mapify.MergeErrorOnConflict}
+		// when
+		merged, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"db":{"host":"a"},"tags":["x"]}`, merged)
+	})
+
+	t.Run("should use first matching rule", func(t *testing.T) {
+		first := decodeObject(t, `{"a":1,"b":1}`)
+		second := decodeObject(t, `{"a":2,"b":2}`)
+		options := mapify.MergeOptions{
+			Rules: []mapify.MergeRule{
+				{Path: ".a", Strategy: mapify.MergeKeepFirst},
+				{Path: ".*", Strategy: mapify.MergeErrorOnConflict},
+			},
+		}
+		// when
+		_, err := mapify.DeepMerge([]map[string]interface{}{first, second}, options)
+		// then
+		assert.ErrorContains(t, err, ".b")
+	})
+
+	t.Run("should return error for invalid rules", func(t *testing.T) {
+		rules := map[string]mapify.MergeRule{
+			"invalid pattern": {Path: "a"},
+			"missing key":     {Path: ".a", Strategy: mapify.MergeUnionByKey},
+		}
+
+		for name, rule := range rules {
+			t.Run(name, func(t *testing.T) {
+				options := mapify.MergeOptions{Rules: []mapify.MergeRule{rule}}
+				// when
+				_, err := mapify.DeepMerge(nil, options)
+				// then
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestMapper_DeepMerge(t *testing.T) {
+	type database struct {
+		Host string
+		Port int
+	}
+
+	type settings struct {
+		Database database
+		Replicas []string
+		Debug    *bool
+	}
+
+	t.Run("should merge mapped values", func(t *testing.T) {
+		debug := true
+		defaults := settings{Database: database{Host: "localhost", Port: 5432}, Replicas: []string{"r1"}}
+		env := map[string]interface{}{"Database": map[string]interface{}{"Host": "db.local"}, "Debug": &debug}
+		options := mapify.MergeOptions{
+			Rules: []mapify.MergeRule{
+				{Path: ".Replicas", Strategy: mapify.MergeAppend},
+			},
+			SkipNil: true,
+		}
+		file := settings{Replicas: []string{"r2"}}
+		// when
+		merged, err := mapify.Mapper{}.DeepMerge([]interface{}{defaults, file, env}, options)
+		// then
+		require.NoError(t, err)
+		assertJSON(t, `{"Database":{"Host":"db.local","Port":0},"Replicas":["r1","r2"],"Debug":true}`, merged)
+	})
+
+	t.Run("should return error when value is not converted to a map", func(t *testing.T) {
+		_, err := mapify.Mapper{}.DeepMerge([]interface{}{"text"}, mapify.MergeOptions{})
+		assert.Error(t, err)
+	})
+}
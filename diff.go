@@ -0,0 +1,263 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ChangeType is the type of Change.
+type ChangeType string
+
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Modified ChangeType = "modified"
+)
+
+// Change describes a single difference between two mapped values.
+type Change struct {
+	Type ChangeType `json:"type"`
+	// Path of the changed value in MapAny syntax, but with keys of the mapped output (after Rename), for example
+	// ".items[0].price". Root value has empty path.
+	Path string `json:"path"`
+	// Old is nil for added values.
+	Old interface{} `json:"old,omitempty"`
+	// New is nil for removed values.
+	New interface{} `json:"new,omitempty"`
+}
+
+// DiffOptions configures Diff.
+type DiffOptions struct {
+	// SliceKeys maps path patterns of slices to names of key fields. Elements of such slices are maps matched by
+	// value of the key field, so reordering elements is not a change. Other slices are compared by index.
+	// Patterns use MapAny path syntax with output keys, where "*" matches any key, "[*]" matches any index and
+	// "**" matches any number of segments, for example ".items" or ".**.items".
+	//
+	// Paths of changes inside slices compared by key contain the key instead of the index, for example
+	// ".items[sku=a].qty", so changes of different elements never have the same path. Key values are formatted
+	// using fmt.Sprint.
+	SliceKeys map[string]string
+}
+
+// Diff maps a and b using MapAny and returns changes between them using DiffMapped.
+func (i Mapper) Diff(a, b interface{}, options DiffOptions) ([]Change, error) {
+	mappedA, err := i.MapAny(a)
+	if err != nil {
+		return nil, err
+	}
+
+	mappedB, err := i.MapAny(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffMapped(mappedA, mappedB, options)
+}
+
+// DiffMapped returns changes between a and b, which are values returned by MapAny or other nested maps
+// and slices. Maps with string keys and slices are compared recursively, and other values are compared using
+// reflect.DeepEqual. Nil and empty maps and slices are equal. Keys of maps are compared in sorted order, so
+// the order of changes is deterministic.
+//
+// Error is returned when a pattern is invalid or when element of slice compared by key has no key.
+func DiffMapped(a, b interface{}, options DiffOptions) ([]Change, error) {
+	d := differ{}
+
+	for pattern, key := range options.SliceKeys {
+		parsed, err := parsePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		d.sliceKeys = append(d.sliceKeys, sliceKey{pattern: parsed, key: key})
+	}
+
+	if err := d.diff("", reflect.ValueOf(a), reflect.ValueOf(b)); err != nil {
+		return nil, err
+	}
+
+	return d.changes, nil
+}
+
+type differ struct {
+	sliceKeys []sliceKey
+	changes   []Change
+}
+
+type sliceKey struct {
+	pattern pathPattern
+	key     string
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) error {
+	a, b = dereferenceInterface(a), dereferenceInterface(b)
+
+	switch {
+	case isStringMap(a) && isStringMap(b):
+		return d.diffMaps(path, a, b)
+	case isSlice(a) && isSlice(b):
+		for _, s := range d.sliceKeys {
+			if s.pattern.match(path) {
+				return d.diffSlicesByKey(path, a, b, s.key)
+			}
+		}
+
+		return d.diffSlicesByIndex(path, a, b)
+	}
+
+	oldValue, newValue := interfaceOrNil(a), interfaceOrNil(b)
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		d.changes = append(d.changes, Change{Type: Modified, Path: path, Old: oldValue, New: newValue})
+	}
+
+	return nil
+}
+
+func (d *differ) diffMaps(path string, a, b reflect.Value) error {
+	keys := map[string]bool{}
+
+	for _, key := range a.MapKeys() {
+		keys[key.String()] = true
+	}
+
+	for _, key := range b.MapKeys() {
+		keys[key.String()] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		keyPath := path + "." + key
+		oldValue := a.MapIndex(reflect.ValueOf(key).Convert(a.Type().Key()))
+		newValue := b.MapIndex(reflect.ValueOf(key).Convert(b.Type().Key()))
+
+		switch {
+		case !oldValue.IsValid():
+			d.changes = append(d.changes, Change{Type: Added, Path: keyPath, New: newValue.Interface()})
+		case !newValue.IsValid():
+			d.changes = append(d.changes, Change{Type: Removed, Path: keyPath, Old: oldValue.Interface()})
+		default:
+			if err := d.diff(keyPath, oldValue, newValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *differ) diffSlicesByIndex(path string, a, b reflect.Value) error {
+	for j := 0; j < a.Len() || j < b.Len(); j++ {
+		indexPath := slicePath(path, j)
+
+		switch {
+		case j >= a.Len():
+			d.changes = append(d.changes, Change{Type: Added, Path: indexPath, New: b.Index(j).Interface()})
+		case j >= b.Len():
+			d.changes = append(d.changes, Change{Type: Removed, Path: indexPath, Old: a.Index(j).Interface()})
+		default:
+			if err := d.diff(indexPath, a.Index(j), b.Index(j)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *differ) diffSlicesByKey(path string, a, b reflect.Value, key string) error {
+	oldIndexes := map[interface{}]int{}
+
+	for j := 0; j < a.Len(); j++ {
+		keyValue, err := elementKey(a.Index(j), key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", slicePath(path, j), err)
+		}
+
+		oldIndexes[keyValue] = j
+	}
+
+	elementPath := func(keyValue interface{}) string {
+		return fmt.Sprintf("%s[%s=%v]", path, key, keyValue)
+	}
+
+	matched := map[int]bool{}
+
+	for j := 0; j < b.Len(); j++ {
+		keyValue, err := elementKey(b.Index(j), key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", slicePath(path, j), err)
+		}
+
+		oldIndex, ok := oldIndexes[keyValue]
+		if !ok {
+			d.changes = append(d.changes, Change{Type: Added, Path: elementPath(keyValue), New: b.Index(j).Interface()})
+
+			continue
+		}
+
+		matched[oldIndex] = true
+
+		if err = d.diff(elementPath(keyValue), a.Index(oldIndex), b.Index(j)); err != nil {
+			return err
+		}
+	}
+
+	for j := 0; j < a.Len(); j++ {
+		if matched[j] {
+			continue
+		}
+
+		keyValue, _ := elementKey(a.Index(j), key) // already checked above
+
+		d.changes = append(d.changes, Change{Type: Removed, Path: elementPath(keyValue), Old: a.Index(j).Interface()})
+	}
+
+	return nil
+}
+
+// elementKey returns value of key field of slice element, which must be a map with string keys.
+func elementKey(element reflect.Value, key string) (interface{}, error) {
+	element = dereferenceInterface(element)
+
+	if !isStringMap(element) {
+		return nil, fmt.Errorf("element is not a map")
+	}
+
+	keyValue := element.MapIndex(reflect.ValueOf(key).Convert(element.Type().Key()))
+	if !keyValue.IsValid() {
+		return nil, fmt.Errorf("element has no key %s", key)
+	}
+
+	if dynamic := dereferenceInterface(keyValue); dynamic.IsValid() && !dynamic.Type().Comparable() {
+		return nil, fmt.Errorf("key %s is not comparable", key)
+	}
+
+	return keyValue.Interface(), nil
+}
+
+func isStringMap(value reflect.Value) bool {
+	return value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String
+}
+
+func isSlice(value reflect.Value) bool {
+	return isContainer(value) && value.Kind() != reflect.Map
+}
+
+func interfaceOrNil(value reflect.Value) interface{} {
+	if !value.IsValid() {
+		return nil
+	}
+
+	return value.Interface()
+}
@@ -0,0 +1,153 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditedOrder struct {
+	ID     int
+	Status string
+	Notes  *string
+	Items  []auditedItem
+	Tags   []string
+}
+
+type auditedItem struct {
+	SKU string
+	Qty int
+}
+
+func TestMapper_Diff(t *testing.T) {
+	note := "note"
+	old := auditedOrder{
+		ID:     1,
+		Status: "new",
+		Items:  []auditedItem{{SKU: "a", Qty: 1}, {SKU: "b", Qty: 2}},
+		Tags:   []string{"x"},
+	}
+	updated := auditedOrder{
+		ID:     1,
+		Status: "paid",
+		Notes:  &note,
+		Items:  []auditedItem{{SKU: "b", Qty: 3}, {SKU: "c", Qty: 1}},
+		Tags:   []string{"x", "y"},
+	}
+	mapper := mapify.Mapper{Rename: lowerRename}
+
+	t.Run("should compare slices by index", func(t *testing.T) {
+		changes, err := mapper.Diff(old, updated, mapify.DiffOptions{})
+		// then
+		require.NoError(t, err)
+		expected := []mapify.Change{
+			{Type: mapify.Modified, Path: ".items[0].qty", Old: 1, New: 3},
+			{Type: mapify.Modified, Path: ".items[0].sku", Old: "a", New: "b"},
+			{Type: mapify.Modified, Path: ".items[1].qty", Old: 2, New: 1},
+			{Type: mapify.Modified, Path: ".items[1].sku", Old: "b", New: "c"},
+			{Type: mapify.Modified, Path: ".notes", Old: (*string)(nil), New: &note},
+			{Type: mapify.Modified, Path: ".status", Old: "new", New: "paid"},
+			{Type: mapify.Added, Path: ".tags[1]", New: "y"},
+		}
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("should compare slices by key", func(t *testing.T) {
+		options := mapify.DiffOptions{SliceKeys: map[string]string{".items": "sku"}}
+		// when
+		changes, err := mapper.Diff(old, updated, options)
+		// then
+		require.NoError(t, err)
+		expected := []mapify.Change{
+			{Type: mapify.Modified, Path: ".items[sku=b].qty", Old: 2, New: 3},
+			{Type: mapify.Added, Path: ".items[sku=c]", New: map[string]interface{}{"sku": "c", "qty": 1}},
+			{Type: mapify.Removed, Path: ".items[sku=a]", Old: map[string]interface{}{"sku": "a", "qty": 1}},
+			{Type: mapify.Modified, Path: ".notes", Old: (*string)(nil), New: &note},
+			{Type: mapify.Modified, Path: ".status", Old: "new", New: "paid"},
+			{Type: mapify.Added, Path: ".tags[1]", New: "y"},
+		}
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("should return no changes for equal values", func(t *testing.T) {
+		changes, err := mapper.Diff(old, old, mapify.DiffOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestDiffMapped(t *testing.T) {
+	t.Run("should report added and removed keys", func(t *testing.T) {
+		a := map[string]interface{}{"removed": 1, "same": map[string]interface{}{"x": 1}}
+		b := map[string]interface{}{"added": 2, "same": map[string]interface{}{"x": 1}}
+		// when
+		changes, err := mapify.DiffMapped(a, b, mapify.DiffOptions{})
+		// then
+		require.NoError(t, err)
+		expected := []mapify.Change{
+			{Type: mapify.Added, Path: ".added", New: 2},
+			{Type: mapify.Removed, Path: ".removed", Old: 1},
+		}
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("should treat nil and empty containers as equal", func(t *testing.T) {
+		a := map[string]interface{}{"slice": []int(nil), "map": map[string]int(nil)}
+		b := map[string]interface{}{"slice": []int{}, "map": map[string]int{}}
+		// when
+		changes, err := mapify.DiffMapped(a, b, mapify.DiffOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("should report changed type", func(t *testing.T) {
+		changes, err := mapify.DiffMapped([]int{1}, map[string]int{}, mapify.DiffOptions{})
+		require.NoError(t, err)
+		expected := []mapify.Change{{Type: mapify.Modified, Path: "", Old: []int{1}, New: map[string]int{}}}
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("should use pattern", func(t *testing.T) {
+		a := map[string]interface{}{"a": map[string]interface{}{"list": []map[string]interface{}{{"id": 1}, {"id": 2}}}}
+		b := map[string]interface{}{"a": map[string]interface{}{"list": []map[string]interface{}{{"id": 2}, {"id": 1}}}}
+		// when
+		changes, err := mapify.DiffMapped(a, b, mapify.DiffOptions{SliceKeys: map[string]string{".**.list": "id"}})
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("should put key in paths of elements compared by key", func(t *testing.T) {
+		a := []map[string]interface{}{{"id": 1, "p": 1}, {"id": 2, "p": 1}}
+		b := []map[string]interface{}{{"id": 2, "p": 2}}
+		// when
+		changes, err := mapify.DiffMapped(a, b, mapify.DiffOptions{SliceKeys: map[string]string{"": "id"}})
+		// then
+		require.NoError(t, err)
+		expected := []mapify.Change{
+			{Type: mapify.Modified, Path: "[id=2].p", Old: 1, New: 2},
+			{Type: mapify.Removed, Path: "[id=1]", Old: map[string]interface{}{"id": 1, "p": 1}},
+		}
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("should return error when element has no key", func(t *testing.T) {
+		a := []map[string]interface{}{{"id": 1}}
+		b := []map[string]interface{}{{"name": "x"}}
+		// when
+		_, err := mapify.DiffMapped(a, b, mapify.DiffOptions{SliceKeys: map[string]string{"": "id"}})
+		// then
+		assert.ErrorContains(t, err, "[0]: element has no key id")
+	})
+
+	t.Run("should return error for invalid pattern", func(t *testing.T) {
+		_, err := mapify.DiffMapped(nil, nil, mapify.DiffOptions{SliceKeys: map[string]string{"items": "id"}})
+		assert.Error(t, err)
+	})
+}
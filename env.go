@@ -0,0 +1,120 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// EnvOptions configures mapping to and from environment variables.
+type EnvOptions struct {
+	// Prefix is prepended to all keys, for example "APP" gives "APP_DB_HOST".
+	Prefix string
+	// Naming converts each key, such as field name renamed by Mapper.Rename, before joining keys with "_".
+	// Default is ScreamingSnakeCase.
+	Naming func(name string) string
+	// CommaJoinedSlices encodes slices of scalar values as "TAGS=a,b". By default, slices are indexed:
+	// "TAGS_0=a", "TAGS_1=b". Slices of structs are always indexed.
+	CommaJoinedSlices bool
+	// Format formats scalar values. Default is FormatValue.
+	Format Formatter
+}
+
+func (o EnvOptions) encoding() flatEncoding {
+	naming := o.Naming
+	if naming == nil {
+		naming = ScreamingSnakeCase
+	}
+
+	format := o.Format
+	if format == nil {
+		format = FormatValue
+	}
+
+	slices := SliceIndexed
+	if o.CommaJoinedSlices {
+		slices = SliceCommaJoined
+	}
+
+	return flatEncoding{
+		nestedKey: func(prefix, name string) string {
+			if prefix == "" {
+				return naming(name)
+			}
+
+			return prefix + "_" + naming(name)
+		},
+		indexKey: func(prefix string, index int) string {
+			return prefix + "_" + strconv.Itoa(index)
+		},
+		slices: slices,
+		format: format,
+	}
+}
+
+// MapEnv maps v using MapAny and converts the result into environment variables, for example
+// struct{ DB struct{ Host string } } is converted into {"DB_HOST": "localhost"}. v must be converted to a map,
+// so it has to be a struct or a map with string key. Nil values, empty slices and empty maps are omitted.
+//
+// Error is returned when two different values are given the same key.
+func (i Mapper) MapEnv(v interface{}, options EnvOptions) (map[string]string, error) {
+	values, err := i.mapFlat(v, options.Prefix, options.encoding())
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string, len(values))
+
+	for key, value := range values {
+		if len(value) > 1 {
+			return nil, fmt.Errorf("duplicate environment variable %s", key)
+		}
+
+		env[key] = value[0]
+	}
+
+	return env, nil
+}
+
+// MapEnviron is like MapEnv, but returns "KEY=value" strings sorted by key, in the format of os.Environ.
+func (i Mapper) MapEnviron(v interface{}, options EnvOptions) ([]string, error) {
+	env, err := i.MapEnv(v, options)
+	if err != nil {
+		return nil, err
+	}
+
+	environ := make([]string, 0, len(env))
+	for key, value := range env {
+		environ = append(environ, key+"="+value)
+	}
+
+	sort.Strings(environ)
+
+	return environ, nil
+}
+
+// DecodeEnv loads environment variables in the format of os.Environ into the struct pointed to by target. It is
+// the inverse of MapEnviron. Keys are built from struct fields using Filter, Rename and options the same way,
+// and values are converted the same way as in DecodeValues. Variables which do not match any field are ignored.
+func (i Mapper) DecodeEnv(environ []string, target interface{}, options EnvOptions) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a non-nil pointer to struct, got %T", target)
+	}
+
+	values := url.Values{}
+
+	for _, variable := range environ {
+		key, val, found := cut(variable, "=")
+		if found {
+			values.Set(key, val)
+		}
+	}
+
+	return i.decodeFlat(values, value, options.Prefix, options.encoding())
+}
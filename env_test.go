@@ -0,0 +1,150 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	DB struct {
+		Host     string
+		PoolSize int
+	}
+	Timeout  time.Duration
+	Tags     []string
+	Replicas []replica
+	Debug    *bool
+}
+
+type replica struct {
+	HTTPPort int
+}
+
+func TestMapper_MapEnv(t *testing.T) {
+	cfg := config{Tags: []string{"a", "b"}, Replicas: []replica{{HTTPPort: 80}}, Timeout: time.Second}
+	cfg.DB.Host = "localhost"
+	cfg.DB.PoolSize = 10
+
+	t.Run("should map struct using default options", func(t *testing.T) {
+		actual, err := mapify.Mapper{}.MapEnv(cfg, mapify.EnvOptions{})
+		require.NoError(t, err)
+		expected := map[string]string{
+			"DB_HOST":              "localhost",
+			"DB_POOL_SIZE":         "10",
+			"TIMEOUT":              "1s",
+			"TAGS_0":               "a",
+			"TAGS_1":               "b",
+			"REPLICAS_0_HTTP_PORT": "80",
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should use prefix, naming, comma joined slices and format", func(t *testing.T) {
+		options := mapify.EnvOptions{
+			Prefix:            "APP",
+			Naming:            strings.ToLower,
+			CommaJoinedSlices: true,
+			Format: func(value interface{}) (string, error) {
+				return fmt.Sprintf("<%v>", value), nil
+			},
+		}
+		// when
+		actual, err := mapify.Mapper{}.MapEnviron(cfg, options)
+		// then
+		require.NoError(t, err)
+		expected := []string{
+			"APP_db_host=<localhost>",
+			"APP_db_poolsize=<10>",
+			"APP_replicas_0_httpport=<80>",
+			"APP_tags=<a>,<b>",
+			"APP_timeout=<1s>",
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return error for duplicate keys", func(t *testing.T) {
+		v := map[string]interface{}{
+			"dbHost": "a",
+			"db":     map[string]string{"host": "b"},
+		}
+		_, err := mapify.Mapper{}.MapEnv(v, mapify.EnvOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when Format failed", func(t *testing.T) {
+		givenError := stringError("err")
+		options := mapify.EnvOptions{
+			Format: func(value interface{}) (string, error) {
+				return "", givenError
+			},
+		}
+		_, err := mapify.Mapper{}.MapEnv(cfg, options)
+		assert.ErrorIs(t, err, givenError)
+	})
+}
+
+func TestMapper_DecodeEnv(t *testing.T) {
+	t.Run("should decode variables produced by MapEnviron", func(t *testing.T) {
+		debug := true
+		expected := config{Tags: []string{"a", "b"}, Replicas: []replica{{HTTPPort: 80}}, Debug: &debug}
+		expected.DB.Host = "localhost"
+
+		for _, options := range []mapify.EnvOptions{{}, {Prefix: "APP", CommaJoinedSlices: true}} {
+			environ, err := mapify.Mapper{}.MapEnviron(expected, options)
+			require.NoError(t, err)
+			environ = append(environ, "PATH=/bin", "INVALID")
+
+			var actual config
+			// when
+			err = mapify.Mapper{}.DecodeEnv(environ, &actual, options)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("should decode self-referencing type", func(t *testing.T) {
+		var actual formNode
+		// when
+		err := mapify.Mapper{}.DecodeEnv([]string{"NAME=a", "NEXT_NEXT_NAME=b"}, &actual, mapify.EnvOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, formNode{Name: "a", Next: &formNode{Next: &formNode{Name: "b"}}}, actual)
+	})
+
+	t.Run("should return error with path and key", func(t *testing.T) {
+		err := mapify.Mapper{}.DecodeEnv([]string{"DB_POOL_SIZE=big"}, &config{}, mapify.EnvOptions{})
+		var decodeError *mapify.DecodeError
+		require.True(t, errors.As(err, &decodeError))
+		assert.Equal(t, ".DB.PoolSize", decodeError.Path)
+		assert.Equal(t, "DB_POOL_SIZE", decodeError.Key)
+	})
+}
+
+func TestScreamingSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Field":        "FIELD",
+		"CreatedAt":    "CREATED_AT",
+		"ID":           "ID",
+		"UserID":       "USER_ID",
+		"HTTPServer":   "HTTP_SERVER",
+		"dbHost":       "DB_HOST",
+		"Address2City": "ADDRESS2_CITY",
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, mapify.ScreamingSnakeCase(name))
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DefaultRule is the name of the rule used when a callback is not set in Mapper.
+const DefaultRule = "default"
+
+// Explanation is a report of all decisions made by MapAny, returned by Mapper.Explain. It can be rendered
+// as text using WriteText or String, or as JSON using json.Marshal.
+type Explanation struct {
+	// Result is the value returned by MapAny.
+	Result interface{} `json:"-"`
+	// Decisions are ordered by the time the path was visited for the first time.
+	Decisions []*Decision `json:"decisions"`
+}
+
+// Decision contains all decisions made for a single path. Only decisions which were made are set, for example
+// Filter is not run for root value, and ShouldConvert is run only for structs, maps and slices.
+type Decision struct {
+	Path          string   `json:"path"`
+	ShouldConvert *Verdict `json:"shouldConvert,omitempty"`
+	Filter        *Verdict `json:"filter,omitempty"`
+	Rename        *Renamed `json:"rename,omitempty"`
+	MapValue      *Mapped  `json:"mapValue,omitempty"`
+	// Error is the error returned by a callback for this path.
+	Error string `json:"error,omitempty"`
+}
+
+// Verdict is the result of ShouldConvert or Filter.
+type Verdict struct {
+	Result bool `json:"result"`
+	// Rule is the name of the function which made the decision, or DefaultRule.
+	Rule string `json:"rule"`
+}
+
+// Renamed is the result of Rename.
+type Renamed struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Rule string `json:"rule"`
+}
+
+// Mapped is the result of MapValue.
+type Mapped struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+	Rule   string      `json:"rule"`
+}
+
+// MarshalJSON encodes Before and After values which are not supported by JSON, such as functions, channels
+// or NaN, as strings formatted the same way as by WriteText.
+func (m Mapped) MarshalJSON() ([]byte, error) {
+	type mapped Mapped // without MarshalJSON method
+
+	return json.Marshal(mapped{Before: explainedJSON(m.Before), After: explainedJSON(m.After), Rule: m.Rule})
+}
+
+// Explain runs MapAny and records every decision made by callbacks: ShouldConvert result, Filter verdict,
+// original and renamed key and value before and after MapValue, together with the name of function which
+// made the decision. Elements are always mapped sequentially.
+//
+// When MapAny fails, the explanation contains decisions made so far, including the failed one.
+func (i Mapper) Explain(v interface{}) (*Explanation, error) {
+	explanation := &Explanation{}
+	decisions := map[string]*Decision{}
+
+	decision := func(path string) *Decision {
+		d, ok := decisions[path]
+		if !ok {
+			d = &Decision{Path: path}
+			decisions[path] = d
+			explanation.Decisions = append(explanation.Decisions, d)
+		}
+
+		return d
+	}
+
+	shouldConvertRule, filterRule := ruleName(i.ShouldConvert), ruleName(i.Filter)
+	renameRule, mapValueRule := ruleName(i.Rename), ruleName(i.MapValue)
+
+	instance := i.newInstance()
+	instance.semaphore = nil
+
+	shouldConvert, filter, rename, mapValue := instance.ShouldConvert, instance.Filter, instance.Rename,
+		instance.MapValue
+
+	instance.ShouldConvert = func(path string, value reflect.Value) (bool, error) {
+		result, err := shouldConvert(path, value)
+		if err != nil {
+			decision(path).Error = err.Error()
+		} else {
+			decision(path).ShouldConvert = &Verdict{Result: result, Rule: shouldConvertRule}
+		}
+
+		return result, err
+	}
+
+	instance.Filter = func(path string, e Element) (bool, error) {
+		result, err := filter(path, e)
+		if err != nil {
+			decision(path).Error = err.Error()
+		} else {
+			decision(path).Filter = &Verdict{Result: result, Rule: filterRule}
+		}
+
+		return result, err
+	}
+
+	instance.Rename = func(path string, e Element) (string, error) {
+		renamed, err := rename(path, e)
+		if err != nil {
+			decision(path).Error = err.Error()
+		} else {
+			decision(path).Rename = &Renamed{From: e.Name(), To: renamed, Rule: renameRule}
+		}
+
+		return renamed, err
+	}
+
+	instance.MapValue = func(path string, e Element) (interface{}, error) {
+		mapped, err := mapValue(path, e)
+		if err != nil {
+			decision(path).Error = err.Error()
+		} else {
+			decision(path).MapValue = &Mapped{Before: e.Interface(), After: mapped, Rule: mapValueRule}
+		}
+
+		return mapped, err
+	}
+
+	result, err := instance.mapAny("", v)
+	explanation.Result = result
+
+	return explanation, err
+}
+
+// ruleName returns the name of function f, such as "github.com/user/project/rules.SnakeCase".
+func ruleName(f interface{}) string {
+	value := reflect.ValueOf(f)
+	if value.IsNil() {
+		return DefaultRule
+	}
+
+	if fn := runtime.FuncForPC(value.Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return "unknown"
+}
+
+// WriteText writes the explanation in human-readable form, one path per paragraph.
+func (e *Explanation) WriteText(w io.Writer) error {
+	var out bytes.Buffer
+
+	for _, d := range e.Decisions {
+		path := d.Path
+		if path == "" {
+			path = "(root)"
+		}
+
+		out.WriteString(path + "\n")
+
+		if d.Filter != nil {
+			fmt.Fprintf(&out, "  Filter: %s (%s)\n", acceptedText(d.Filter.Result), d.Filter.Rule)
+		}
+
+		if d.Rename != nil {
+			fmt.Fprintf(&out, "  Rename: %q -> %q (%s)\n", d.Rename.From, d.Rename.To, d.Rename.Rule)
+		}
+
+		if d.MapValue != nil {
+			fmt.Fprintf(&out, "  MapValue: %s -> %s (%s)\n", formatExplained(d.MapValue.Before),
+				formatExplained(d.MapValue.After), d.MapValue.Rule)
+		}
+
+		if d.ShouldConvert != nil {
+			fmt.Fprintf(&out, "  ShouldConvert: %t (%s)\n", d.ShouldConvert.Result, d.ShouldConvert.Rule)
+		}
+
+		if d.Error != "" {
+			fmt.Fprintf(&out, "  Error: %s\n", d.Error)
+		}
+	}
+
+	_, err := out.WriteTo(w)
+
+	return err
+}
+
+// String returns the explanation in the same form as WriteText.
+func (e *Explanation) String() string {
+	var s strings.Builder
+
+	_ = e.WriteText(&s)
+
+	return s.String()
+}
+
+func acceptedText(accepted bool) string {
+	if accepted {
+		return "accepted"
+	}
+
+	return "rejected"
+}
+
+func explainedJSON(v interface{}) json.RawMessage {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		encoded, _ = json.Marshal(formatExplained(v)) // strings are always encoded
+	}
+
+	return encoded
+}
+
+func formatExplained(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprint(v)
+}
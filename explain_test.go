@@ -0,0 +1,166 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type explained struct {
+	Name     string
+	Password string
+	Nested   explainedNested
+}
+
+type explainedNested struct {
+	Value int
+}
+
+func rejectPassword(path string, e mapify.Element) (bool, error) {
+	return e.Name() != "Password", nil
+}
+
+func TestMapper_Explain(t *testing.T) {
+	mapper := mapify.Mapper{
+		Filter: rejectPassword,
+		Rename: func(path string, e mapify.Element) (string, error) {
+			return strings.ToLower(e.Name()), nil
+		},
+	}
+	v := explained{Name: "John", Password: "secret", Nested: explainedNested{Value: 1}}
+
+	t.Run("should record all decisions", func(t *testing.T) {
+		explanation, err := mapper.Explain(v)
+		// then
+		require.NoError(t, err)
+		expectedResult := map[string]interface{}{
+			"name":   "John",
+			"nested": map[string]interface{}{"value": 1},
+		}
+		assert.Equal(t, expectedResult, explanation.Result)
+
+		paths := make([]string, len(explanation.Decisions))
+		for j, d := range explanation.Decisions {
+			paths[j] = d.Path
+		}
+
+		assert.Equal(t, []string{"", ".Name", ".Password", ".Nested", ".Nested.Value"}, paths)
+
+		password := explanation.Decisions[2]
+		assert.Equal(t, &mapify.Verdict{Result: false, Rule: "github.com/elgopher/mapify_test.rejectPassword"},
+			password.Filter)
+		assert.Nil(t, password.Rename)
+		assert.Nil(t, password.MapValue)
+
+		nested := explanation.Decisions[3]
+		assert.Equal(t, &mapify.Verdict{Result: true, Rule: mapify.DefaultRule}, nested.ShouldConvert)
+		assert.Equal(t, "nested", nested.Rename.To)
+		assert.Equal(t, v.Nested, nested.MapValue.Before)
+		assert.Equal(t, mapify.DefaultRule, nested.MapValue.Rule)
+		assert.Contains(t, nested.Rename.Rule, "TestMapper_Explain")
+	})
+
+	t.Run("should render text", func(t *testing.T) {
+		explanation, err := mapify.Mapper{Filter: rejectPassword}.Explain(v)
+		require.NoError(t, err)
+		// when
+		text := explanation.String()
+		// then
+		expected := `(root)
+  ShouldConvert: true (default)
+.Name
+  Filter: accepted (github.com/elgopher/mapify_test.rejectPassword)
+  Rename: "Name" -> "Name" (default)
+  MapValue: "John" -> "John" (default)
+.Password
+  Filter: rejected (github.com/elgopher/mapify_test.rejectPassword)
+.Nested
+  Filter: accepted (github.com/elgopher/mapify_test.rejectPassword)
+  Rename: "Nested" -> "Nested" (default)
+  MapValue: {1} -> {1} (default)
+  ShouldConvert: true (default)
+.Nested.Value
+  Filter: accepted (github.com/elgopher/mapify_test.rejectPassword)
+  Rename: "Value" -> "Value" (default)
+  MapValue: 1 -> 1 (default)
+`
+		assert.Equal(t, expected, text)
+	})
+
+	t.Run("should render JSON", func(t *testing.T) {
+		explanation, err := mapify.Mapper{}.Explain(map[string]int{"a": 1})
+		require.NoError(t, err)
+		// when
+		actual, err := json.Marshal(explanation)
+		// then
+		require.NoError(t, err)
+		expected := `{"decisions": [
+			{"path": "", "shouldConvert": {"result": true, "rule": "default"}},
+			{
+				"path": ".a",
+				"filter": {"result": true, "rule": "default"},
+				"rename": {"from": "a", "to": "a", "rule": "default"},
+				"mapValue": {"before": 1, "after": 1, "rule": "default"}
+			}
+		]}`
+		assert.JSONEq(t, expected, string(actual))
+	})
+
+	t.Run("should render JSON for values not supported by JSON", func(t *testing.T) {
+		explanation, err := mapify.Mapper{}.Explain(map[string]interface{}{"f": func() {}, "n": math.NaN()})
+		require.NoError(t, err)
+		// when
+		actual, err := json.Marshal(explanation)
+		// then
+		require.NoError(t, err)
+		var decoded struct {
+			Decisions []struct {
+				Path     string
+				MapValue struct{ Before, After interface{} }
+			}
+		}
+		require.NoError(t, json.Unmarshal(actual, &decoded))
+		require.Len(t, decoded.Decisions, 3)
+		for _, d := range decoded.Decisions {
+			switch d.Path {
+			case ".f":
+				assert.IsType(t, "", d.MapValue.Before)
+				assert.IsType(t, "", d.MapValue.After)
+			case ".n":
+				assert.Equal(t, "NaN", d.MapValue.Before)
+				assert.Equal(t, "NaN", d.MapValue.After)
+			}
+		}
+	})
+
+	t.Run("should record error", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+				if path == ".Nested" {
+					return false, errors.New("nested failed")
+				}
+
+				return true, nil
+			},
+		}
+		// when
+		explanation, err := mapper.Explain(v)
+		// then
+		require.Error(t, err)
+		last := explanation.Decisions[len(explanation.Decisions)-1]
+		assert.Equal(t, ".Nested", last.Path)
+		assert.Equal(t, "nested failed", last.Error)
+		assert.Nil(t, last.ShouldConvert)
+		assert.Contains(t, explanation.String(), "  Error: nested failed\n")
+	})
+}
@@ -0,0 +1,274 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FieldMask selects a subset of fields, for example for partial responses like "fields=id,name,items.price".
+// Names in FieldMask are names of map keys after Rename, joined with dots. Elements of slices are not named:
+// "items.price" selects price of each element of items. Zero FieldMask selects all fields.
+type FieldMask struct {
+	root *fieldMaskNode
+}
+
+type fieldMaskNode struct {
+	// all is true when all nested fields are selected
+	all      bool
+	children map[string]*fieldMaskNode
+}
+
+// ParseFieldMask parses comma-separated list of paths, such as "id,name,items.price". The list can be preceded
+// by parameter name, for example "fields=id,name".
+func ParseFieldMask(s string) (FieldMask, error) {
+	if _, list, found := cut(s, "="); found {
+		s = list
+	}
+
+	return NewFieldMask(strings.Split(s, ","))
+}
+
+// NewFieldMask creates FieldMask from paths, such as "items.price".
+func NewFieldMask(paths []string) (FieldMask, error) {
+	root := &fieldMaskNode{children: map[string]*fieldMaskNode{}}
+
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+
+		node := root
+
+		for _, name := range strings.Split(path, ".") {
+			if name == "" {
+				return FieldMask{}, fmt.Errorf("invalid path %q in field mask", path)
+			}
+
+			if node.all {
+				break
+			}
+
+			child, ok := node.children[name]
+			if !ok {
+				child = &fieldMaskNode{children: map[string]*fieldMaskNode{}}
+				node.children[name] = child
+			}
+
+			node = child
+		}
+
+		node.all = true
+		node.children = nil
+	}
+
+	return FieldMask{root: root}, nil
+}
+
+// String returns sorted, comma-separated list of paths.
+func (m FieldMask) String() string {
+	if m.root == nil {
+		return ""
+	}
+
+	var paths []string
+
+	var collect func(prefix string, node *fieldMaskNode)
+	collect = func(prefix string, node *fieldMaskNode) {
+		if node.all {
+			paths = append(paths, prefix)
+			return
+		}
+
+		for name, child := range node.children {
+			collect(strings.TrimPrefix(prefix+"."+name, "."), child)
+		}
+	}
+	collect("", m.root)
+
+	sort.Strings(paths)
+
+	return strings.Join(paths, ",")
+}
+
+// WithFieldMask returns a copy of Mapper which maps only fields selected by m and their ancestors. Fields are
+// selected when both m and Mapper.Filter accept them. Names in m are compared with names returned by
+// Mapper.Rename. Returned Mapper can be used concurrently when the original one can.
+func (i Mapper) WithFieldMask(m FieldMask) Mapper {
+	if m.root == nil {
+		return i
+	}
+
+	instantiate := i.instantiate
+	i.instantiate = func(instance Mapper) Mapper {
+		if instantiate != nil {
+			instance = instantiate(instance)
+		}
+
+		return m.instantiate(instance)
+	}
+
+	return i
+}
+
+// instantiate returns instance with Filter accepting fields selected by the mask. Accepted elements are tracked
+// by paths within a single call.
+func (m FieldMask) instantiate(instance Mapper) Mapper {
+	filter, rename := instance.Filter, instance.Rename
+
+	// path -> *fieldMaskNode for accepted elements with only some nested fields selected
+	var partial sync.Map
+
+	instance.Filter = func(path string, e Element) (bool, error) {
+		parent := m.root
+
+		if parentPath := parentElementPath(path); parentPath != "" {
+			node, ok := partial.Load(parentPath)
+			if !ok {
+				return filter(path, e) // all nested fields of parent were selected
+			}
+
+			parent = node.(*fieldMaskNode)
+		}
+
+		name, err := rename(path, e)
+		if err != nil {
+			return false, err
+		}
+
+		node, ok := parent.children[name]
+		if !ok {
+			return false, nil
+		}
+
+		if !node.all {
+			partial.Store(path, node)
+		}
+
+		return filter(path, e)
+	}
+
+	return instance
+}
+
+// parentElementPath returns path of the struct field or map entry containing element at path, for example
+// ".Items" for ".Items[0].Price".
+func parentElementPath(path string) string {
+	if j := strings.LastIndexAny(path, ".["); j >= 0 && path[j] == '.' {
+		path = path[:j]
+	}
+
+	for strings.HasSuffix(path, "]") {
+		path = path[:strings.LastIndexByte(path, '[')]
+	}
+
+	return path
+}
+
+// Validate checks that all paths in the mask exist in type t, which fields are renamed using rename. Fields of
+// maps and interfaces are not checked. Error contains all unknown paths.
+func (m FieldMask) Validate(t reflect.Type, rename Rename) error {
+	if m.root == nil {
+		return nil
+	}
+
+	if rename == nil {
+		rename = noRename
+	}
+
+	var unknown []string
+
+	if err := validateFieldMask(m.root, t, "", "", rename, &unknown); err != nil {
+		return err
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return fmt.Errorf("unknown fields in field mask: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+func validateFieldMask(node *fieldMaskNode, t reflect.Type, path, maskPath string, rename Rename,
+	unknown *[]string) error {
+
+	t, path = elementType(t, path)
+
+	if t.Kind() == reflect.Interface || t.Kind() == reflect.Map {
+		return nil
+	}
+
+	fields, err := renamedFields(t, path, rename)
+	if err != nil {
+		return err
+	}
+
+	for name, child := range node.children {
+		childMaskPath := strings.TrimPrefix(maskPath+"."+name, ".")
+
+		field, ok := fields[name]
+		if !ok {
+			*unknown = append(*unknown, childMaskPath)
+			continue
+		}
+
+		err = validateFieldMask(child, field.Type, path+"."+field.Name, childMaskPath, rename, unknown)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// elementType dereferences pointers and returns type of slice elements, so the returned type is the type
+// of a value which fields can be selected. path is updated accordingly.
+func elementType(t reflect.Type, path string) (reflect.Type, string) {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+		case reflect.Slice, reflect.Array:
+			t = t.Elem()
+			path = slicePath(path, 0)
+		default:
+			return t, path
+		}
+	}
+}
+
+// renamedFields returns exported fields of t by their names returned by rename. For types other than struct
+// empty map is returned.
+func renamedFields(t reflect.Type, path string, rename Rename) (map[string]reflect.StructField, error) {
+	fields := map[string]reflect.StructField{}
+
+	if t.Kind() != reflect.Struct {
+		return fields, nil
+	}
+
+	value := reflect.New(t).Elem()
+
+	for j := 0; j < t.NumField(); j++ {
+		field := t.Field(j)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		element := Element{name: field.Name, Value: value.Field(j), field: &field}
+
+		name, err := rename(path+"."+field.Name, element)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[name] = field
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,205 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maskedOrder struct {
+	ID       int
+	Name     string
+	Customer maskedCustomer
+	Items    []maskedItem
+	Grid     [][]maskedItem
+	Labels   map[string]string
+}
+
+type maskedCustomer struct {
+	Name  string
+	Email string
+}
+
+type maskedItem struct {
+	Price int
+	Qty   int
+}
+
+func lowerRename(path string, e mapify.Element) (string, error) {
+	return strings.ToLower(e.Name()), nil
+}
+
+// clientOrder has field Customer named "client" and field Other named "customer" by jsonRename.
+type clientOrder struct {
+	Customer maskedCustomer `json:"client"`
+	Other    maskedCustomer `json:"customer"`
+}
+
+func jsonRename(path string, e mapify.Element) (string, error) {
+	if field, ok := e.StructField(); ok {
+		if name := field.Tag.Get("json"); name != "" {
+			return name, nil
+		}
+	}
+
+	return lowerRename(path, e)
+}
+
+func TestParseFieldMask(t *testing.T) {
+	t.Run("should parse", func(t *testing.T) {
+		tests := map[string]string{
+			"id":                          "id",
+			"fields=id,name,items.price":  "id,items.price,name",
+			" id , customer.name ":        "customer.name,id",
+			"items.price,items":           "items",
+			"items,items.price":           "items",
+			"customer.name,customer.name": "customer.name",
+		}
+
+		for s, expected := range tests {
+			mask, err := mapify.ParseFieldMask(s)
+			require.NoError(t, err)
+			assert.Equal(t, expected, mask.String())
+		}
+	})
+
+	t.Run("should return error for invalid mask", func(t *testing.T) {
+		for _, s := range []string{"", "fields=", "id,", "items..price", ".id"} {
+			_, err := mapify.ParseFieldMask(s)
+			assert.Error(t, err, s)
+		}
+	})
+}
+
+func TestMapper_WithFieldMask(t *testing.T) {
+	v := maskedOrder{
+		ID:       1,
+		Name:     "order",
+		Customer: maskedCustomer{Name: "John", Email: "john@example.com"},
+		Items:    []maskedItem{{Price: 1, Qty: 2}, {Price: 3, Qty: 4}},
+		Grid:     [][]maskedItem{{{Price: 5, Qty: 6}}},
+		Labels:   map[string]string{"a": "1", "b": "2"},
+	}
+
+	t.Run("should select fields and their ancestors", func(t *testing.T) {
+		mask, err := mapify.ParseFieldMask("fields=id,customer.name,items.price,grid.qty,labels.b")
+		require.NoError(t, err)
+		mapper := mapify.Mapper{Rename: lowerRename}.WithFieldMask(mask)
+		// when
+		actual, err := mapper.MapAny(v)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"id":       1,
+			"customer": map[string]interface{}{"name": "John"},
+			"items":    []map[string]interface{}{{"price": 1}, {"price": 3}},
+			"grid":     [][]map[string]interface{}{{{"qty": 6}}},
+			"labels":   map[string]interface{}{"b": "2"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should select all nested fields", func(t *testing.T) {
+		mask, err := mapify.NewFieldMask([]string{"Customer"})
+		require.NoError(t, err)
+		mapper := mapify.Mapper{}.WithFieldMask(mask)
+		// when
+		actual, err := mapper.MapAny([]maskedOrder{v})
+		// then
+		require.NoError(t, err)
+		expected := []map[string]interface{}{
+			{"Customer": map[string]interface{}{"Name": "John", "Email": "john@example.com"}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("zero mask should select all fields", func(t *testing.T) {
+		actual, err := mapify.Mapper{}.WithFieldMask(mapify.FieldMask{}).MapAny(v)
+		require.NoError(t, err)
+		assert.Len(t, actual, 6)
+	})
+
+	t.Run("should also apply Filter of Mapper", func(t *testing.T) {
+		mask, err := mapify.ParseFieldMask("ID,Name")
+		require.NoError(t, err)
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return path != ".Name", nil
+			},
+		}
+		// when
+		actual, err := mapper.WithFieldMask(mask).MapAny(v)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"ID": 1}, actual)
+	})
+
+	t.Run("should reuse Mapper for different types", func(t *testing.T) {
+		mask, err := mapify.ParseFieldMask("client,customer.name")
+		require.NoError(t, err)
+		mapper := mapify.Mapper{Rename: jsonRename}.WithFieldMask(mask)
+		_, err = mapper.MapAny(v)
+		require.NoError(t, err)
+		client := clientOrder{
+			Customer: maskedCustomer{Name: "John", Email: "john@example.com"},
+			Other:    maskedCustomer{Name: "Jane", Email: "jane@example.com"},
+		}
+		// when
+		actual, err := mapper.MapAny(client)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"client":   map[string]interface{}{"name": "John", "email": "john@example.com"},
+			"customer": map[string]interface{}{"name": "Jane"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return error when rename failed", func(t *testing.T) {
+		renameError := errors.New("rename failed")
+		mask, err := mapify.ParseFieldMask("id")
+		require.NoError(t, err)
+		mapper := mapify.Mapper{
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return "", renameError
+			},
+		}
+		// when
+		_, err = mapper.WithFieldMask(mask).MapAny(v)
+		// then
+		assert.ErrorIs(t, err, renameError)
+	})
+}
+
+func TestFieldMask_Validate(t *testing.T) {
+	orderType := reflect.TypeOf(&maskedOrder{})
+
+	t.Run("should accept existing fields", func(t *testing.T) {
+		mask, err := mapify.ParseFieldMask("id,customer.email,items.qty,grid.price,labels.anything.nested")
+		require.NoError(t, err)
+		assert.NoError(t, mask.Validate(orderType, lowerRename))
+	})
+
+	t.Run("should report all unknown fields", func(t *testing.T) {
+		mask, err := mapify.ParseFieldMask("id,nmae,items.prize,name.first,customer.name")
+		require.NoError(t, err)
+		// when
+		err = mask.Validate(orderType, lowerRename)
+		// then
+		assert.EqualError(t, err, "unknown fields in field mask: items.prize, name.first, nmae")
+	})
+
+	t.Run("should use Go names when rename is nil", func(t *testing.T) {
+		mask, err := mapify.ParseFieldMask("ID,Customer.Name")
+		require.NoError(t, err)
+		assert.NoError(t, mask.Validate(orderType, nil))
+	})
+}
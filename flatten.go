@@ -0,0 +1,122 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// IndexStyle specifies how slice indexes are put into flattened keys.
+type IndexStyle int
+
+const (
+	// IndexAsKey puts index as a regular key, for example "items.0.price".
+	IndexAsKey IndexStyle = iota
+	// IndexInBrackets puts index in square brackets, for example "items[0].price".
+	IndexInBrackets
+)
+
+// FlattenOptions configures Flatten.
+type FlattenOptions struct {
+	// Separator is put between keys. Default is ".".
+	Separator string
+	// IndexStyle specifies how slice indexes are put into keys. Default is IndexAsKey.
+	IndexStyle IndexStyle
+	// MaxDepth is the maximum number of keys joined together. Values found deeper are kept nested.
+	// Zero means no limit.
+	MaxDepth int
+}
+
+// MapFlat maps v using MapAny and flattens the result using Flatten.
+func (i Mapper) MapFlat(v interface{}, options FlattenOptions) (map[string]interface{}, error) {
+	mapped, err := i.MapAny(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return Flatten(mapped, options), nil
+}
+
+// Flatten converts nested maps with string keys and slices into a flat map, for example
+// {"user": {"address": {"city": "X"}}, "items": [{"price": 3}]} is converted into
+// {"user.address.city": "X", "items.0.price": 3}.
+//
+// Empty maps and slices are kept as values. []byte is never flattened. When v is not a map nor slice, it is
+// returned under the empty key.
+func Flatten(v interface{}, options FlattenOptions) map[string]interface{} {
+	if options.Separator == "" {
+		options.Separator = "."
+	}
+
+	result := map[string]interface{}{}
+	flattenValue(result, "", 0, reflect.ValueOf(v), options)
+
+	return result
+}
+
+func flattenValue(result map[string]interface{}, key string, depth int, value reflect.Value, options FlattenOptions) {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		result[key] = nil
+		return
+	}
+
+	if options.MaxDepth > 0 && depth >= options.MaxDepth {
+		result[key] = value.Interface()
+		return
+	}
+
+	if !isContainer(value) || value.Len() == 0 {
+		if depth > 0 || !isContainer(value) {
+			result[key] = value.Interface()
+		}
+
+		return
+	}
+
+	if value.Kind() == reflect.Map {
+		iter := value.MapRange()
+		for iter.Next() {
+			flattenValue(result, joinKey(key, iter.Key().String(), options), depth+1, iter.Value(), options)
+		}
+
+		return
+	}
+
+	for j := 0; j < value.Len(); j++ {
+		flattenValue(result, indexKey(key, j, options), depth+1, value.Index(j), options)
+	}
+}
+
+// isContainer returns true for values which are flattened: maps with string key, slices and arrays except bytes.
+func isContainer(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Map:
+		return value.Type().Key().Kind() == reflect.String
+	case reflect.Slice, reflect.Array:
+		return value.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+func joinKey(prefix, key string, options FlattenOptions) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + options.Separator + key
+}
+
+func indexKey(prefix string, index int, options FlattenOptions) string {
+	if options.IndexStyle == IndexInBrackets {
+		return prefix + "[" + strconv.Itoa(index) + "]"
+	}
+
+	return joinKey(prefix, strconv.Itoa(index), options)
+}
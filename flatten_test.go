@@ -0,0 +1,104 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	nested := map[string]interface{}{
+		"user": map[string]interface{}{
+			"address": map[string]interface{}{"city": "X"},
+		},
+		"items": []map[string]interface{}{
+			{"price": 3},
+			{"price": 4},
+		},
+		"tags":  []string{"a", "b"},
+		"bytes": []byte("bytes"),
+		"empty": map[string]interface{}{},
+		"nil":   nil,
+	}
+
+	t.Run("should flatten using default options", func(t *testing.T) {
+		actual := mapify.Flatten(nested, mapify.FlattenOptions{})
+		expected := map[string]interface{}{
+			"user.address.city": "X",
+			"items.0.price":     3,
+			"items.1.price":     4,
+			"tags.0":            "a",
+			"tags.1":            "b",
+			"bytes":             []byte("bytes"),
+			"empty":             map[string]interface{}{},
+			"nil":               nil,
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should use separator and brackets for indexes", func(t *testing.T) {
+		actual := mapify.Flatten(nested["items"], mapify.FlattenOptions{
+			Separator:  "_",
+			IndexStyle: mapify.IndexInBrackets,
+		})
+		expected := map[string]interface{}{
+			"[0]_price": 3,
+			"[1]_price": 4,
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should keep values nested after max depth", func(t *testing.T) {
+		actual := mapify.Flatten(nested["user"], mapify.FlattenOptions{MaxDepth: 1})
+		expected := map[string]interface{}{
+			"address": map[string]interface{}{"city": "X"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should flatten empty map to empty map", func(t *testing.T) {
+		actual := mapify.Flatten(map[string]interface{}{}, mapify.FlattenOptions{})
+		assert.Empty(t, actual)
+	})
+
+	t.Run("should put primitive under empty key", func(t *testing.T) {
+		actual := mapify.Flatten(1, mapify.FlattenOptions{})
+		assert.Equal(t, map[string]interface{}{"": 1}, actual)
+	})
+}
+
+func TestMapper_MapFlat(t *testing.T) {
+	t.Run("should map and flatten struct", func(t *testing.T) {
+		type address struct{ City string }
+
+		type user struct {
+			Name    string
+			Address address
+		}
+
+		actual, err := mapify.Mapper{}.MapFlat(user{Name: "n", Address: address{City: "c"}}, mapify.FlattenOptions{})
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"Name":         "n",
+			"Address.City": "c",
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return error when mapping failed", func(t *testing.T) {
+		givenError := stringError("err")
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return false, givenError
+			},
+		}
+
+		_, err := mapper.MapFlat(struct{ Field string }{}, mapify.FlattenOptions{})
+		assert.ErrorIs(t, err, givenError)
+	})
+}
@@ -0,0 +1,74 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+//go:build go1.18
+
+package mapify
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrNotConverted is returned by MapStruct and MapSlice when given value cannot be converted to the requested type.
+var ErrNotConverted = errors.New("value not converted")
+
+// MapStruct maps a struct (or a pointer to struct) to map[string]interface{} using Mapper m.
+//
+// Error wrapping ErrNotConverted is returned when v is not a struct, a nil pointer, or when m.ShouldConvert
+// decides not to convert the struct.
+func MapStruct[T any](m Mapper, v T) (map[string]interface{}, error) {
+	t := typeOf[T]()
+
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not a struct or a non-nil pointer to struct", ErrNotConverted, t)
+	}
+
+	result, err := m.MapAny(v)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: ShouldConvert returned false for %s", ErrNotConverted, t)
+	}
+
+	return mapped, nil
+}
+
+// MapSlice maps a slice of structs (or maps with string key) to []map[string]interface{} using Mapper m.
+//
+// Error wrapping ErrNotConverted is returned when elements are not structs or maps with string key, or when
+// m.ShouldConvert decides not to convert the slice.
+func MapSlice[T any](m Mapper, v []T) ([]map[string]interface{}, error) {
+	t := typeOf[T]()
+
+	convertible := t.Kind() == reflect.Struct ||
+		(t.Kind() == reflect.Map && t.Key().Kind() == reflect.String)
+	if !convertible {
+		return nil, fmt.Errorf("%w: element type %s is not a struct or a map with string key", ErrNotConverted, t)
+	}
+
+	result, err := m.MapAny(v)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped, ok := result.([]map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: ShouldConvert returned false for []%s", ErrNotConverted, t)
+	}
+
+	return mapped, nil
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,151 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+//go:build go1.18
+
+package mapify_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapStruct(t *testing.T) {
+	type structType struct{ Field string }
+
+	t.Run("should map struct", func(t *testing.T) {
+		actual, err := mapify.MapStruct(mapify.Mapper{}, structType{Field: "v"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"Field": "v"}, actual)
+	})
+
+	t.Run("should map pointer to struct", func(t *testing.T) {
+		actual, err := mapify.MapStruct(mapify.Mapper{}, &structType{Field: "v"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"Field": "v"}, actual)
+	})
+
+	t.Run("should map struct given as interface", func(t *testing.T) {
+		var v interface{} = structType{Field: "v"}
+		actual, err := mapify.MapStruct(mapify.Mapper{}, v)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"Field": "v"}, actual)
+	})
+
+	t.Run("should return error for unsupported values", func(t *testing.T) {
+		var nilPointer *structType
+
+		tests := map[string]func() error{
+			"int": func() error {
+				_, err := mapify.MapStruct(mapify.Mapper{}, 1)
+				return err
+			},
+			"nil pointer": func() error {
+				_, err := mapify.MapStruct(mapify.Mapper{}, nilPointer)
+				return err
+			},
+			"nil interface": func() error {
+				_, err := mapify.MapStruct[interface{}](mapify.Mapper{}, nil)
+				return err
+			},
+			"map": func() error {
+				_, err := mapify.MapStruct(mapify.Mapper{}, map[string]string{})
+				return err
+			},
+		}
+
+		for name, mapStruct := range tests {
+			t.Run(name, func(t *testing.T) {
+				assert.ErrorIs(t, mapStruct(), mapify.ErrNotConverted)
+			})
+		}
+	})
+
+	t.Run("should return error when ShouldConvert returned false", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+				return false, nil
+			},
+		}
+		// when
+		actual, err := mapify.MapStruct(mapper, structType{})
+		// then
+		assert.Nil(t, actual)
+		assert.ErrorIs(t, err, mapify.ErrNotConverted)
+	})
+
+	t.Run("should return error returned by callback", func(t *testing.T) {
+		givenError := stringError("err")
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return false, givenError
+			},
+		}
+		// when
+		_, err := mapify.MapStruct(mapper, structType{})
+		// then
+		assert.ErrorIs(t, err, givenError)
+	})
+}
+
+func TestMapSlice(t *testing.T) {
+	type structType struct{ Field string }
+
+	t.Run("should map slice of structs", func(t *testing.T) {
+		actual, err := mapify.MapSlice(mapify.Mapper{}, []structType{{Field: "v"}})
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]interface{}{{"Field": "v"}}, actual)
+	})
+
+	t.Run("should map slice of maps", func(t *testing.T) {
+		actual, err := mapify.MapSlice(mapify.Mapper{}, []map[string]int{{"Field": 1}})
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]interface{}{{"Field": 1}}, actual)
+	})
+
+	t.Run("should map nil slice", func(t *testing.T) {
+		actual, err := mapify.MapSlice[structType](mapify.Mapper{}, nil)
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	t.Run("should return error for unsupported element types", func(t *testing.T) {
+		tests := map[string]func() error{
+			"ints": func() error {
+				_, err := mapify.MapSlice(mapify.Mapper{}, []int{1})
+				return err
+			},
+			"pointers to structs": func() error {
+				_, err := mapify.MapSlice(mapify.Mapper{}, []*structType{{}})
+				return err
+			},
+			"maps with int key": func() error {
+				_, err := mapify.MapSlice(mapify.Mapper{}, []map[int]string{{}})
+				return err
+			},
+		}
+
+		for name, mapSlice := range tests {
+			t.Run(name, func(t *testing.T) {
+				assert.ErrorIs(t, mapSlice(), mapify.ErrNotConverted)
+			})
+		}
+	})
+
+	t.Run("should return error when ShouldConvert returned false", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+				return false, nil
+			},
+		}
+		// when
+		actual, err := mapify.MapSlice(mapper, []structType{{}})
+		// then
+		assert.Nil(t, actual)
+		assert.ErrorIs(t, err, mapify.ErrNotConverted)
+	})
+}
@@ -0,0 +1,33 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+// Package naming splits Go identifiers into words, so all naming conventions of mapify and mapifygen
+// agree on word boundaries.
+package naming
+
+import "unicode"
+
+// Words splits name into words, keeping acronyms together: "HTTPServerID" -> "HTTP", "Server", "ID".
+func Words(name string) []string {
+	runes := []rune(name)
+
+	var result []string
+
+	start := 0
+
+	for j := 1; j < len(runes); j++ {
+		prev, current := runes[j-1], runes[j]
+		nextIsLower := j+1 < len(runes) && unicode.IsLower(runes[j+1])
+
+		if unicode.IsUpper(current) && (!unicode.IsUpper(prev) || nextIsLower) {
+			result = append(result, string(runes[start:j]))
+			start = j
+		}
+	}
+
+	if start < len(runes) {
+		result = append(result, string(runes[start:]))
+	}
+
+	return result
+}
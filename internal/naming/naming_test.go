@@ -0,0 +1,27 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package naming_test
+
+import (
+	"testing"
+
+	"github.com/elgopher/mapify/internal/naming"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"":             nil,
+		"Field":        {"Field"},
+		"dbHost":       {"db", "Host"},
+		"HTTPServerID": {"HTTP", "Server", "ID"},
+		"Address2City": {"Address2", "City"},
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, naming.Words(name))
+		})
+	}
+}
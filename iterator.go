@@ -0,0 +1,40 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import "fmt"
+
+// Iterator returns next value from a sequence, for example a database cursor. ok is false when there are
+// no more values. If error is returned then the iteration is aborted.
+type Iterator func() (v interface{}, ok bool, err error)
+
+// MapIterator maps each value returned by next and passes the result to yield, one by one. Next value is not
+// requested until yield returns. Values are mapped with paths of slice elements: "[0]", "[1]" and so on.
+//
+// Iteration stops on first error returned by next, yield or any Mapper callback. The error is wrapped and returned.
+func (i Mapper) MapIterator(next Iterator, yield func(mapped interface{}) error) error {
+	instance := i.newInstance()
+
+	for j := 0; ; j++ {
+		v, ok, err := next()
+		if err != nil {
+			return fmt.Errorf("Iterator failed: %w", err)
+		}
+
+		if !ok {
+			return nil
+		}
+
+		path := slicePath("", j)
+
+		mapped, err := instance.mapAny(path, v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if err = yield(mapped); err != nil {
+			return fmt.Errorf("yield failed: %w", err)
+		}
+	}
+}
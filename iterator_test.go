@@ -0,0 +1,116 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct{ ID int }
+
+func sliceIterator(values ...interface{}) mapify.Iterator {
+	return func() (interface{}, bool, error) {
+		if len(values) == 0 {
+			return nil, false, nil
+		}
+
+		v := values[0]
+		values = values[1:]
+
+		return v, true, nil
+	}
+}
+
+func TestMapper_MapIterator(t *testing.T) {
+	t.Run("should map all values", func(t *testing.T) {
+		var actual []interface{}
+		// when
+		err := mapify.Mapper{}.MapIterator(sliceIterator(row{ID: 1}, row{ID: 2}), func(mapped interface{}) error {
+			actual = append(actual, mapped)
+			return nil
+		})
+		// then
+		require.NoError(t, err)
+		expected := []interface{}{
+			map[string]interface{}{"ID": 1},
+			map[string]interface{}{"ID": 2},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should use slice element paths", func(t *testing.T) {
+		var paths []string
+
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				paths = append(paths, path)
+				return true, nil
+			},
+		}
+		// when
+		err := mapper.MapIterator(sliceIterator(row{}, row{}), func(interface{}) error { return nil })
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"[0].ID", "[1].ID"}, paths)
+	})
+
+	t.Run("should not request next value until yield returned", func(t *testing.T) {
+		requested, yielded := 0, 0
+		iterator := sliceIterator(row{}, row{}, row{})
+		next := func() (interface{}, bool, error) {
+			requested++
+			assert.Equal(t, yielded, requested-1)
+			return iterator()
+		}
+		// when
+		err := mapify.Mapper{}.MapIterator(next, func(interface{}) error {
+			yielded++
+			return nil
+		})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, yielded)
+	})
+
+	t.Run("should return error", func(t *testing.T) {
+		givenError := stringError("err")
+
+		tests := map[string]struct {
+			mapper mapify.Mapper
+			next   mapify.Iterator
+			yield  func(interface{}) error
+		}{
+			"iterator failed": {
+				next: func() (interface{}, bool, error) {
+					return nil, false, givenError
+				},
+				yield: func(interface{}) error { return nil },
+			},
+			"yield failed": {
+				next:  sliceIterator(row{}),
+				yield: func(interface{}) error { return givenError },
+			},
+			"mapping failed": {
+				mapper: mapify.Mapper{
+					Filter: func(path string, e mapify.Element) (bool, error) {
+						return false, givenError
+					},
+				},
+				next:  sliceIterator(row{}),
+				yield: func(interface{}) error { return nil },
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				err := test.mapper.MapIterator(test.next, test.yield)
+				assert.ErrorIs(t, err, givenError)
+			})
+		}
+	})
+}
@@ -0,0 +1,348 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EncodeJSON writes JSON encoding of v to w, followed by a newline character. The output is the same as
+// json.Marshal of the value returned by MapAny (except the order of struct fields), but the intermediate maps
+// are not created.
+func (i Mapper) EncodeJSON(w io.Writer, v interface{}) error {
+	return i.NewJSONEncoder(w).Encode(v)
+}
+
+// JSONEncoder writes JSON values to an output stream applying ShouldConvert, Filter, Rename and MapValue
+// on the fly.
+type JSONEncoder struct {
+	mapper   Mapper
+	w        io.Writer
+	prefix   string
+	indent   string
+	sortKeys bool
+}
+
+// NewJSONEncoder returns a new encoder that writes to w.
+func (i Mapper) NewJSONEncoder(w io.Writer) *JSONEncoder {
+	return &JSONEncoder{mapper: i, w: w}
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded value as if indented by json.MarshalIndent.
+// Calling SetIndent("", "") disables indentation.
+func (e *JSONEncoder) SetIndent(prefix, indent string) {
+	e.prefix = prefix
+	e.indent = indent
+}
+
+// SetSortKeys instructs the encoder to sort keys of converted structs. By default, keys of structs are written
+// in order of declaration of struct fields. Keys of maps are always sorted.
+func (e *JSONEncoder) SetSortKeys(sortKeys bool) {
+	e.sortKeys = sortKeys
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline character. When error is returned,
+// part of the JSON could have been already written.
+func (e *JSONEncoder) Encode(v interface{}) error {
+	w := &jsonWriter{
+		JSONEncoder: e,
+		Mapper:      e.mapper.newInstance(),
+		out:         bufio.NewWriter(e.w),
+	}
+
+	if err := w.writeAny("", v, 0); err != nil {
+		return err
+	}
+
+	_ = w.out.WriteByte('\n')
+
+	return w.out.Flush()
+}
+
+type jsonWriter struct {
+	*JSONEncoder
+	Mapper
+	out *bufio.Writer
+}
+
+type jsonEntry struct {
+	key   string
+	path  string
+	value interface{}
+}
+
+func (w *jsonWriter) writeAny(path string, v interface{}, depth int) error {
+	reflectValue := reflect.ValueOf(v)
+
+	switch {
+	case reflectValue.Kind() == reflect.Struct ||
+		(reflectValue.Kind() == reflect.Ptr && reflectValue.Elem().Kind() == reflect.Struct):
+		shouldConvert, err := w.ShouldConvert(path, reflectValue)
+		if err != nil {
+			return fmt.Errorf("ShouldConvert failed: %w", err)
+		}
+
+		if !shouldConvert {
+			return w.writeLeaf(v, depth)
+		}
+
+		return w.writeStruct(path, reflectValue, depth)
+	case reflectValue.Kind() == reflect.Map && reflectValue.Type().Key().Kind() == reflect.String:
+		shouldConvert, err := w.ShouldConvert(path, reflectValue)
+		if err != nil {
+			return fmt.Errorf("ShouldConvert failed: %w", err)
+		}
+
+		if !shouldConvert {
+			return w.writeLeaf(v, depth)
+		}
+
+		return w.writeStringMap(path, reflectValue, depth)
+	case reflectValue.Kind() == reflect.Slice:
+		return w.writeSlice(path, reflectValue, depth)
+	default:
+		return w.writeLeaf(v, depth)
+	}
+}
+
+func (w *jsonWriter) writeLeaf(v interface{}, depth int) error {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if w.indent == "" && w.prefix == "" {
+		_, _ = w.out.Write(encoded)
+
+		return nil
+	}
+
+	var indented bytes.Buffer
+
+	_ = json.Indent(&indented, encoded, w.prefix+strings.Repeat(w.indent, depth), w.indent)
+	_, _ = indented.WriteTo(w.out)
+
+	return nil
+}
+
+func (w *jsonWriter) writeStruct(path string, reflectValue reflect.Value, depth int) error {
+	reflectValue = dereference(reflectValue)
+	reflectType := reflectValue.Type()
+
+	var entries []jsonEntry
+
+	for j := 0; j < reflectType.NumField(); j++ {
+		field := reflectType.Field(j)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		element := Element{name: field.Name, Value: reflectValue.Field(j), field: &field}
+
+		var err error
+
+		entries, err = w.appendEntry(entries, fieldPath, element)
+		if err != nil {
+			return err
+		}
+	}
+
+	if w.sortKeys {
+		sortEntries(entries)
+	}
+
+	return w.writeObject(entries, depth)
+}
+
+func (w *jsonWriter) writeStringMap(path string, reflectValue reflect.Value, depth int) error {
+	var entries []jsonEntry
+
+	for _, key := range reflectValue.MapKeys() {
+		fieldName := key.String()
+		element := Element{name: fieldName, Value: reflectValue.MapIndex(key)}
+
+		var err error
+
+		entries, err = w.appendEntry(entries, path+"."+fieldName, element)
+		if err != nil {
+			return err
+		}
+	}
+
+	sortEntries(entries)
+
+	return w.writeObject(entries, depth)
+}
+
+// appendEntry runs Filter, Rename and MapValue for the element. Entry with the same key as one already added
+// replaces its value, the same way as assigning a map key does in MapAny.
+func (w *jsonWriter) appendEntry(entries []jsonEntry, fieldPath string, element Element) ([]jsonEntry, error) {
+	element, err := w.prepare(fieldPath, element)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted, filterErr := w.Filter(fieldPath, element)
+	if filterErr != nil {
+		return nil, fmt.Errorf("Filter failed: %w", filterErr)
+	}
+
+	if !accepted {
+		return entries, nil
+	}
+
+	renamed, renameErr := w.Rename(fieldPath, element)
+	if renameErr != nil {
+		return nil, fmt.Errorf("Rename failed: %w", renameErr)
+	}
+
+	mappedValue, mapErr := w.MapValue(fieldPath, element)
+	if mapErr != nil {
+		return nil, fmt.Errorf("MapValue failed: %w", mapErr)
+	}
+
+	entry := jsonEntry{key: renamed, path: fieldPath, value: mappedValue}
+
+	for j := range entries {
+		if entries[j].key == renamed {
+			entries[j] = entry
+
+			return entries, nil
+		}
+	}
+
+	return append(entries, entry), nil
+}
+
+func sortEntries(entries []jsonEntry) {
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].key < entries[b].key
+	})
+}
+
+func (w *jsonWriter) writeObject(entries []jsonEntry, depth int) error {
+	_ = w.out.WriteByte('{')
+
+	for j, entry := range entries {
+		if j > 0 {
+			_ = w.out.WriteByte(',')
+		}
+
+		w.newLine(depth + 1)
+
+		key, _ := json.Marshal(entry.key)
+		_, _ = w.out.Write(key)
+		_ = w.out.WriteByte(':')
+
+		if w.indent != "" || w.prefix != "" {
+			_ = w.out.WriteByte(' ')
+		}
+
+		if err := w.writeAny(entry.path, entry.value, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if len(entries) > 0 {
+		w.newLine(depth)
+	}
+
+	_ = w.out.WriteByte('}')
+
+	return nil
+}
+
+func (w *jsonWriter) writeSlice(path string, reflectValue reflect.Value, depth int) error {
+	elemType := reflectValue.Type().Elem()
+
+	switch {
+	case elemType.Kind() == reflect.Struct ||
+		(elemType.Kind() == reflect.Map && elemType.Key().Kind() == reflect.String):
+		shouldConvert, err := w.ShouldConvert(path, reflectValue)
+		if err != nil {
+			return fmt.Errorf("ShouldConvert failed: %w", err)
+		}
+
+		if !shouldConvert {
+			return w.writeLeaf(reflectValue.Interface(), depth)
+		}
+
+		return w.writeArray(reflectValue.Len(), depth, func(j int) error {
+			if elemType.Kind() == reflect.Struct {
+				return w.writeStruct(slicePath(path, j), reflectValue.Index(j), depth+1)
+			}
+
+			return w.writeStringMap(slicePath(path, j), reflectValue.Index(j), depth+1)
+		})
+	case elemType.Kind() == reflect.Slice &&
+		(elemType.Elem().Kind() == reflect.Struct ||
+			(elemType.Elem().Kind() == reflect.Map && elemType.Elem().Key().Kind() == reflect.String)):
+		shouldConvert, err := w.ShouldConvert(path, reflectValue)
+		if err != nil {
+			return fmt.Errorf("ShouldConvert failed: %w", err)
+		}
+
+		if !shouldConvert {
+			return w.writeLeaf(reflectValue.Interface(), depth)
+		}
+
+		if reflectValue.Len() == 0 {
+			_, _ = w.out.WriteString("null") // MapAny returns nil [][]map[string]interface{} for empty 2d slice
+
+			return nil
+		}
+
+		return w.writeArray(reflectValue.Len(), depth, func(j int) error {
+			return w.writeSlice(slicePath(path, j), reflectValue.Index(j), depth+1)
+		})
+	default:
+		return w.writeLeaf(reflectValue.Interface(), depth)
+	}
+}
+
+func (w *jsonWriter) writeArray(length, depth int, writeElement func(j int) error) error {
+	_ = w.out.WriteByte('[')
+
+	for j := 0; j < length; j++ {
+		if j > 0 {
+			_ = w.out.WriteByte(',')
+		}
+
+		w.newLine(depth + 1)
+
+		if err := writeElement(j); err != nil {
+			return err
+		}
+	}
+
+	if length > 0 {
+		w.newLine(depth)
+	}
+
+	_ = w.out.WriteByte(']')
+
+	return nil
+}
+
+func (w *jsonWriter) newLine(depth int) {
+	if w.indent == "" && w.prefix == "" {
+		return
+	}
+
+	_ = w.out.WriteByte('\n')
+	_, _ = w.out.WriteString(w.prefix)
+
+	for j := 0; j < depth; j++ {
+		_, _ = w.out.WriteString(w.indent)
+	}
+}
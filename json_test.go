@@ -0,0 +1,160 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapper_EncodeJSON(t *testing.T) {
+	type nested struct {
+		B string
+		A int
+	}
+
+	type root struct {
+		Z       string
+		Nested  nested
+		Pointer *nested
+		Slice   []nested
+		Slice2D [][]nested
+		Empty2D [][]nested
+		Map     map[string]nested
+		Ints    []int
+		Time    time.Time
+		private string
+	}
+
+	value := root{
+		Z:       "<z>",
+		Nested:  nested{B: "b", A: 1},
+		Slice:   []nested{{B: "1"}, {B: "2"}},
+		Slice2D: [][]nested{{{B: "1"}}, {}},
+		Map:     map[string]nested{"y": {}, "x": {A: 2}},
+		Ints:    []int{1, 2},
+		Time:    time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	mappers := map[string]mapify.Mapper{
+		"default": {},
+		"filter and rename": {
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return path != ".Nested.A", nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return strings.ToLower(e.Name()), nil
+			},
+		},
+		"map value": {
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				if path == ".Pointer" {
+					return nested{B: "mapped"}, nil
+				}
+
+				return e.Interface(), nil
+			},
+		},
+	}
+
+	values := map[string]interface{}{
+		"struct":           value,
+		"pointer":          &value,
+		"slice of structs": []root{value, {}},
+		"map":              map[string]interface{}{"key": value},
+		"primitive":        "str",
+		"nil":              nil,
+	}
+
+	t.Run("should encode the same JSON as json.Marshal of MapAny result", func(t *testing.T) {
+		for mapperName, mapper := range mappers {
+			for valueName, v := range values {
+				t.Run(mapperName+" "+valueName, func(t *testing.T) {
+					mapped, err := mapper.MapAny(v)
+					require.NoError(t, err)
+					expected, err := json.Marshal(mapped)
+					require.NoError(t, err)
+
+					var out bytes.Buffer
+					encoder := mapper.NewJSONEncoder(&out)
+					encoder.SetSortKeys(true)
+					// when
+					err = encoder.Encode(v)
+					// then
+					require.NoError(t, err)
+					assert.Equal(t, string(expected)+"\n", out.String())
+				})
+			}
+		}
+	})
+
+	t.Run("should indent JSON the same way as json.MarshalIndent", func(t *testing.T) {
+		for valueName, v := range values {
+			t.Run(valueName, func(t *testing.T) {
+				mapped, err := mapify.Mapper{}.MapAny(v)
+				require.NoError(t, err)
+				expected, err := json.MarshalIndent(mapped, ">", "  ")
+				require.NoError(t, err)
+
+				var out bytes.Buffer
+				encoder := mapify.Mapper{}.NewJSONEncoder(&out)
+				encoder.SetSortKeys(true)
+				encoder.SetIndent(">", "  ")
+				// when
+				err = encoder.Encode(v)
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, string(expected)+"\n", out.String())
+			})
+		}
+	})
+
+	t.Run("should write struct fields in declaration order", func(t *testing.T) {
+		var out bytes.Buffer
+		// when
+		err := mapify.Mapper{}.EncodeJSON(&out, nested{B: "b", A: 1})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `{"B":"b","A":1}`+"\n", out.String())
+	})
+
+	t.Run("should write renamed duplicate key once", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return "key", nil
+			},
+		}
+		var out bytes.Buffer
+		// when
+		err := mapper.EncodeJSON(&out, nested{B: "b", A: 1})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `{"key":1}`+"\n", out.String())
+	})
+
+	t.Run("should return error when callback returned error", func(t *testing.T) {
+		givenError := stringError("err")
+		mapper := mapify.Mapper{
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				return nil, givenError
+			},
+		}
+		// when
+		err := mapper.EncodeJSON(&bytes.Buffer{}, value)
+		// then
+		assert.ErrorIs(t, err, givenError)
+	})
+
+	t.Run("should return error when value cannot be marshaled", func(t *testing.T) {
+		err := mapify.Mapper{}.EncodeJSON(&bytes.Buffer{}, struct{ Chan chan int }{})
+		assert.Error(t, err)
+	})
+}
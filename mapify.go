@@ -16,6 +16,24 @@ type Mapper struct {
 	Filter        Filter
 	Rename        Rename
 	MapValue      MapValue
+	// Observer is notified about mapping of each element. Nil means no observer.
+	Observer Observer
+
+	// Workers is the maximum number of additional goroutines used by a single MapAny call to map elements of large
+	// slices. Zero or one means that everything is mapped sequentially by the calling goroutine. When Workers is
+	// greater than one, all callbacks (ShouldConvert, Filter, Rename and MapValue) must be safe for concurrent use.
+	Workers int
+	// ParallelThreshold is the minimum length of a slice which is split across workers. Shorter slices are
+	// mapped sequentially. Zero means 1000.
+	ParallelThreshold int
+
+	semaphore chan struct{}
+	// instantiate is run by newInstance, so each call gets callbacks with its own state.
+	instantiate func(Mapper) Mapper
+	// prepareElement is run once for each element before any callback. Used to mask sensitive values.
+	prepareElement func(i Mapper, path string, e Element) (Element, error)
+	// elementAdded is run after element was added to the result map with key. Used to keep fields order.
+	elementAdded func(path string, e Element, key string)
 }
 
 // ShouldConvert returns true when value should be converted to map. The value can be a struct, map[string]any or slice.
@@ -70,7 +88,7 @@ func (i Mapper) mapAny(path string, v interface{}) (interface{}, error) {
 		(reflectValue.Kind() == reflect.Ptr && reflectValue.Elem().Kind() == reflect.Struct):
 		shouldConvert, err := i.ShouldConvert(path, reflectValue)
 		if err != nil {
-			return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+			return nil, i.observeError(path, fmt.Errorf("ShouldConvert failed: %w", err))
 		}
 
 		if !shouldConvert {
@@ -81,7 +99,7 @@ func (i Mapper) mapAny(path string, v interface{}) (interface{}, error) {
 	case reflectValue.Kind() == reflect.Map && reflectValue.Type().Key().Kind() == reflect.String:
 		shouldConvert, err := i.ShouldConvert(path, reflectValue)
 		if err != nil {
-			return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+			return nil, i.observeError(path, fmt.Errorf("ShouldConvert failed: %w", err))
 		}
 
 		if !shouldConvert {
@@ -113,6 +131,23 @@ func (i Mapper) newInstance() Mapper {
 		i.MapValue = interfaceValue
 	}
 
+	if i.Observer == nil {
+		i.Observer = NoopObserver{}
+	}
+
+	if i.Workers > 1 {
+		i.semaphore = make(chan struct{}, i.Workers)
+
+		if i.ParallelThreshold <= 0 {
+			i.ParallelThreshold = defaultParallelThreshold
+		}
+	}
+
+	if instantiate := i.instantiate; instantiate != nil {
+		i.instantiate = nil
+		i = instantiate(i)
+	}
+
 	return i
 }
 
@@ -170,33 +205,62 @@ func (i Mapper) mapStringMap(path string, reflectValue reflect.Value) (map[strin
 }
 
 func (i Mapper) mapElement(fieldPath string, element Element, result map[string]interface{}) error {
+	i.Observer.OnEnter(fieldPath)
+	defer i.Observer.OnExit(fieldPath)
+
+	element, err := i.prepare(fieldPath, element)
+	if err != nil {
+		return i.observeError(fieldPath, err)
+	}
+
 	accepted, filterErr := i.Filter(fieldPath, element)
 	if filterErr != nil {
-		return fmt.Errorf("Filter failed: %w", filterErr)
+		return i.observeError(fieldPath, fmt.Errorf("Filter failed: %w", filterErr))
 	}
 
-	if accepted {
-		renamed, renameErr := i.Rename(fieldPath, element)
-		if renameErr != nil {
-			return fmt.Errorf("Rename failed: %w", renameErr)
-		}
+	if !accepted {
+		i.Observer.OnFiltered(fieldPath)
 
-		mappedValue, mapErr := i.MapValue(fieldPath, element)
-		if mapErr != nil {
-			return fmt.Errorf("MapValue failed: %w", mapErr)
-		}
+		return nil
+	}
 
-		finalValue, err := i.mapAny(fieldPath, mappedValue)
-		if err != nil {
-			return err
-		}
+	renamed, renameErr := i.Rename(fieldPath, element)
+	if renameErr != nil {
+		return i.observeError(fieldPath, fmt.Errorf("Rename failed: %w", renameErr))
+	}
+
+	i.Observer.OnRenamed(fieldPath, element.name, renamed)
+
+	mappedValue, mapErr := i.MapValue(fieldPath, element)
+	if mapErr != nil {
+		return i.observeError(fieldPath, fmt.Errorf("MapValue failed: %w", mapErr))
+	}
 
-		result[renamed] = finalValue
+	i.Observer.OnMapped(fieldPath)
+
+	finalValue, err := i.mapAny(fieldPath, mappedValue)
+	if err != nil {
+		return err
+	}
+
+	result[renamed] = finalValue
+
+	if i.elementAdded != nil {
+		i.elementAdded(fieldPath, element, renamed)
 	}
 
 	return nil
 }
 
+// prepare returns element which should be passed to callbacks.
+func (i Mapper) prepare(path string, e Element) (Element, error) {
+	if i.prepareElement == nil {
+		return e, nil
+	}
+
+	return i.prepareElement(i, path, e)
+}
+
 func (i Mapper) mapSlice(path string, reflectValue reflect.Value) (_ interface{}, err error) {
 	kind := reflectValue.Type().Elem().Kind()
 
@@ -204,23 +268,14 @@ func (i Mapper) mapSlice(path string, reflectValue reflect.Value) (_ interface{}
 	case reflect.Struct:
 		shouldConvert, err := i.ShouldConvert(path, reflectValue)
 		if err != nil {
-			return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+			return nil, i.observeError(path, fmt.Errorf("ShouldConvert failed: %w", err))
 		}
 
 		if !shouldConvert {
 			return reflectValue.Interface(), nil
 		}
 
-		slice := make([]map[string]interface{}, reflectValue.Len())
-
-		for j := 0; j < reflectValue.Len(); j++ {
-			slice[j], err = i.mapStruct(slicePath(path, j), reflectValue.Index(j))
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		return slice, nil
+		return i.mapSliceElements(path, reflectValue, i.observed(i.mapStruct))
 	case reflect.Map:
 		if reflectValue.Type().Elem().Key().Kind() != reflect.String {
 			return reflectValue.Interface(), nil
@@ -228,23 +283,14 @@ func (i Mapper) mapSlice(path string, reflectValue reflect.Value) (_ interface{}
 
 		shouldConvert, err := i.ShouldConvert(path, reflectValue)
 		if err != nil {
-			return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+			return nil, i.observeError(path, fmt.Errorf("ShouldConvert failed: %w", err))
 		}
 
 		if !shouldConvert {
 			return reflectValue.Interface(), nil
 		}
 
-		slice := make([]map[string]interface{}, reflectValue.Len())
-
-		for j := 0; j < reflectValue.Len(); j++ {
-			slice[j], err = i.mapStringMap(slicePath(path, j), reflectValue.Index(j))
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		return slice, nil
+		return i.mapSliceElements(path, reflectValue, i.observed(i.mapStringMap))
 	case reflect.Slice:
 		sliceElem := reflectValue.Type().Elem().Elem()
 
@@ -253,7 +299,7 @@ func (i Mapper) mapSlice(path string, reflectValue reflect.Value) (_ interface{}
 
 			shouldConvert, err := i.ShouldConvert(path, reflectValue)
 			if err != nil {
-				return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+				return nil, i.observeError(path, fmt.Errorf("ShouldConvert failed: %w", err))
 			}
 
 			if !shouldConvert {
@@ -263,7 +309,12 @@ func (i Mapper) mapSlice(path string, reflectValue reflect.Value) (_ interface{}
 			var slice [][]map[string]interface{}
 
 			for j := 0; j < reflectValue.Len(); j++ {
-				indexValue, err := i.mapSlice(slicePath(path, j), reflectValue.Index(j))
+				indexPath := slicePath(path, j)
+
+				i.Observer.OnEnter(indexPath)
+				indexValue, err := i.mapSlice(indexPath, reflectValue.Index(j))
+				i.Observer.OnExit(indexPath)
+
 				if err != nil {
 					return nil, err
 				}
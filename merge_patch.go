@@ -0,0 +1,129 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Merge applies JSON Merge Patch (RFC 7386) to a copy of target and returns the copy: nil values in patch
+// delete keys, nested maps are merged recursively and all other values (including slices) replace values
+// in target. Nil pointers, slices and maps are treated as null, the same way as in JSON.
+//
+// Maps with string keys and slices of target are copied into map[string]interface{} and []interface{},
+// so neither target nor patch is modified.
+func Merge(target, patch map[string]interface{}) map[string]interface{} {
+	merged, _ := mergePatch(copyContainers(reflect.ValueOf(target)), reflect.ValueOf(patch)).(map[string]interface{})
+	if merged == nil {
+		merged = map[string]interface{}{}
+	}
+
+	return merged
+}
+
+func mergePatch(target interface{}, patch reflect.Value) interface{} {
+	patch = dereferenceInterface(patch)
+
+	if !isStringMap(patch) || patch.IsNil() {
+		return copyContainers(patch)
+	}
+
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = map[string]interface{}{}
+	}
+
+	iter := patch.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+
+		if isNull(dereferenceInterface(iter.Value())) {
+			delete(result, key)
+			continue
+		}
+
+		result[key] = mergePatch(result[key], iter.Value())
+	}
+
+	return result
+}
+
+// CreateMergePatch maps old and updated using MapAny and creates JSON Merge Patch using function
+// CreateMergePatch. Both values must be converted to maps.
+func (i Mapper) CreateMergePatch(old, updated interface{}) (map[string]interface{}, error) {
+	mappedOld, err := i.mapToMap(old)
+	if err != nil {
+		return nil, err
+	}
+
+	mappedUpdated, err := i.mapToMap(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateMergePatch(mappedOld, mappedUpdated), nil
+}
+
+func (i Mapper) mapToMap(v interface{}) (map[string]interface{}, error) {
+	mapped, err := i.MapAny(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := mapped.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%T was not converted to a map", v)
+	}
+
+	return m, nil
+}
+
+// CreateMergePatch creates JSON Merge Patch (RFC 7386), which transforms old into updated when applied using Merge.
+// Keys removed from old are set to nil, nested maps are compared recursively, and other changed values
+// (including slices) are put into the patch as a whole. Values are compared using reflect.DeepEqual.
+//
+// Merge Patch cannot set null values, so keys which are null in updated are deleted when the patch is applied.
+func CreateMergePatch(old, updated map[string]interface{}) map[string]interface{} {
+	return createMergePatch(reflect.ValueOf(old), reflect.ValueOf(updated))
+}
+
+func createMergePatch(old, updated reflect.Value) map[string]interface{} {
+	patch := map[string]interface{}{}
+
+	iter := old.MapRange()
+	for iter.Next() {
+		if !updated.MapIndex(iter.Key().Convert(updated.Type().Key())).IsValid() {
+			patch[iter.Key().String()] = nil
+		}
+	}
+
+	iter = updated.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		newValue := dereferenceInterface(iter.Value())
+		oldValue := old.MapIndex(reflect.ValueOf(key).Convert(old.Type().Key()))
+
+		if !oldValue.IsValid() {
+			patch[key] = iter.Value().Interface()
+			continue
+		}
+
+		oldValue = dereferenceInterface(oldValue)
+
+		if isStringMap(oldValue) && isStringMap(newValue) && !oldValue.IsNil() && !newValue.IsNil() {
+			if nested := createMergePatch(oldValue, newValue); len(nested) > 0 {
+				patch[key] = nested
+			}
+
+			continue
+		}
+
+		if !reflect.DeepEqual(interfaceOrNil(oldValue), interfaceOrNil(newValue)) {
+			patch[key] = iter.Value().Interface()
+		}
+	}
+
+	return patch
+}
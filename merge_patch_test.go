@@ -0,0 +1,106 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	t.Run("should pass examples from RFC 7386", func(t *testing.T) {
+		tests := []struct {
+			target, patch, expected string
+		}{
+			{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+			{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+			{`{"a":"b"}`, `{"a":null}`, `{}`},
+			{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+			{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+			{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+			{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+			{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+			{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+			{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		}
+
+		for _, test := range tests {
+			t.Run(test.patch, func(t *testing.T) {
+				target := decodeObject(t, test.target)
+				patch := decodeObject(t, test.patch)
+				// when
+				actual := mapify.Merge(target, patch)
+				// then
+				assertJSON(t, test.expected, actual)
+				assert.Equal(t, decodeObject(t, test.target), target, "target must not be modified")
+			})
+		}
+	})
+
+	t.Run("should merge mapper output shapes", func(t *testing.T) {
+		var nilPointer *string
+
+		target := map[string]interface{}{
+			"items": []map[string]interface{}{{"price": 1}},
+			"meta":  map[string]interface{}{"a": "1", "b": "2"},
+		}
+		patch := map[string]interface{}{
+			"meta":  map[string]string{"b": "3"},
+			"items": []map[string]interface{}(nil),
+			"note":  nilPointer,
+		}
+		// when
+		actual := mapify.Merge(target, patch)
+		// then
+		expected := map[string]interface{}{
+			"meta": map[string]interface{}{"a": "1", "b": "3"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return empty map for nil target", func(t *testing.T) {
+		actual := mapify.Merge(nil, nil)
+		assert.Equal(t, map[string]interface{}{}, actual)
+	})
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	t.Run("should create patch", func(t *testing.T) {
+		old := decodeObject(t, `{"a":"b","c":{"d":"e","f":"g"},"h":[1],"same":{"x":1}}`)
+		updated := decodeObject(t, `{"a":"z","c":{"d":"e"},"h":[1,2],"i":{"j":1},"same":{"x":1}}`)
+		// when
+		patch := mapify.CreateMergePatch(old, updated)
+		// then
+		assertJSON(t, `{"a":"z","c":{"f":null},"h":[1,2],"i":{"j":1}}`, patch)
+		assert.Equal(t, updated, mapify.Merge(old, patch))
+	})
+
+	t.Run("mapper should create patch from structs", func(t *testing.T) {
+		old := auditedOrder{ID: 1, Status: "new", Tags: []string{"a"}}
+		updated := auditedOrder{ID: 1, Status: "paid", Tags: []string{"a"}}
+		// when
+		patch, err := mapify.Mapper{Rename: lowerRename}.CreateMergePatch(old, updated)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"status": "paid"}, patch)
+	})
+
+	t.Run("mapper should return error when value was not converted to map", func(t *testing.T) {
+		_, err := mapify.Mapper{}.CreateMergePatch([]int{}, map[string]int{})
+		assert.Error(t, err)
+	})
+}
+
+func decodeObject(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &m))
+
+	return m
+}
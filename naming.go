@@ -0,0 +1,15 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"strings"
+
+	"github.com/elgopher/mapify/internal/naming"
+)
+
+// ScreamingSnakeCase converts name, such as Go field name, to SCREAMING_SNAKE_CASE: "DBHost" -> "DB_HOST".
+func ScreamingSnakeCase(name string) string {
+	return strings.ToUpper(strings.Join(naming.Words(name), "_"))
+}
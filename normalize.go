@@ -0,0 +1,189 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+)
+
+// maxNormalizeDepth limits recursion, so cyclic values are handed over to encoding/json, which reports an error.
+const maxNormalizeDepth = 1000
+
+// NormalizeOptions configures Normalize.
+type NormalizeOptions struct {
+	// UseNumber converts numbers to json.Number instead of float64, the same way as json.Decoder.UseNumber does.
+	UseNumber bool
+}
+
+// MapNormalized maps v using MapAny and normalizes the result using Normalize.
+func (i Mapper) MapNormalized(v interface{}, options NormalizeOptions) (interface{}, error) {
+	mapped, err := i.MapAny(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return Normalize(mapped, options)
+}
+
+// Normalize converts v into JSON-native types: map[string]interface{}, []interface{}, string, bool, float64
+// (or json.Number when options.UseNumber is true) and nil. The result is equal to what json.Unmarshal returns
+// for json.Marshal(v), so []byte is converted to base64 string, named types are converted to their underlying
+// types and nil slices and maps are converted to nil.
+//
+// Maps with string keys, slices and scalars are converted directly. Other values (such as structs not converted
+// by MapAny or values implementing json.Marshaler) are marshaled and unmarshaled using encoding/json.
+// Error is returned for values which cannot be encoded in JSON, such as NaN or channels.
+func Normalize(v interface{}, options NormalizeOptions) (interface{}, error) {
+	return normalizeValue(reflect.ValueOf(v), options, 0)
+}
+
+func normalizeValue(value reflect.Value, options NormalizeOptions, depth int) (interface{}, error) {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		if value.Kind() == reflect.Ptr && hasJSONMethod(value.Type()) {
+			break
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	if depth > maxNormalizeDepth || hasJSONMethod(value.Type()) || value.Type() == jsonNumberType {
+		return normalizeUsingJSON(value.Interface(), options)
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.String:
+		if !utf8.ValidString(value.String()) {
+			return normalizeUsingJSON(value.Interface(), options) // invalid bytes are replaced by encoding/json
+		}
+
+		return value.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if options.UseNumber {
+			return json.Number(strconv.FormatInt(value.Int(), 10)), nil
+		}
+
+		return float64(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if options.UseNumber {
+			return json.Number(strconv.FormatUint(value.Uint(), 10)), nil
+		}
+
+		return float64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return normalizeFloat(value, options)
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		if value.Type().Elem().Kind() == reflect.Uint8 && !hasJSONMethod(reflect.PtrTo(value.Type().Elem())) {
+			return base64.StdEncoding.EncodeToString(value.Bytes()), nil
+		}
+
+		return normalizeElements(value, options, depth)
+	case reflect.Array:
+		return normalizeElements(value, options, depth)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return normalizeUsingJSON(value.Interface(), options)
+		}
+
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		result := make(map[string]interface{}, value.Len())
+
+		iter := value.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if !utf8.ValidString(key) {
+				return normalizeUsingJSON(value.Interface(), options)
+			}
+
+			normalized, err := normalizeValue(iter.Value(), options, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = normalized
+		}
+
+		return result, nil
+	default:
+		return normalizeUsingJSON(value.Interface(), options)
+	}
+}
+
+// hasJSONMethod returns true when t has custom JSON encoding.
+func hasJSONMethod(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// normalizeFloat uses encoding/json, because float32 is encoded with 32-bit precision, and json.Number must be
+// exactly the same as the one decoded from JSON.
+func normalizeFloat(value reflect.Value, options NormalizeOptions) (interface{}, error) {
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	if options.UseNumber {
+		return json.Number(encoded), nil
+	}
+
+	return strconv.ParseFloat(string(encoded), 64)
+}
+
+func normalizeElements(value reflect.Value, options NormalizeOptions, depth int) (interface{}, error) {
+	result := make([]interface{}, value.Len())
+
+	for j := range result {
+		normalized, err := normalizeValue(value.Index(j), options, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		result[j] = normalized
+	}
+
+	return result, nil
+}
+
+func normalizeUsingJSON(v interface{}, options NormalizeOptions) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	if options.UseNumber {
+		decoder.UseNumber()
+	}
+
+	var result interface{}
+	err = decoder.Decode(&result)
+
+	return result, err
+}
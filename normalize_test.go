@@ -0,0 +1,156 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type status string
+
+type leaf struct {
+	Name   string `json:"name"`
+	Hidden string `json:"-"`
+}
+
+type normalized struct {
+	Int8       int8
+	Uint64     uint64
+	Float32    float32
+	Status     status
+	Bytes      []byte
+	NilBytes   []byte
+	Array      [2]byte
+	Time       time.Time
+	IP         net.IP
+	Pointer    *int
+	Leaf       leaf
+	IntKeys    map[int]string
+	Statuses   map[status][]status
+	NilMap     map[string]int
+	NilSlice   []int
+	Nested     []map[string]interface{}
+	Number     json.Number
+	Raw        json.RawMessage
+	InvalidStr string
+}
+
+func TestNormalize(t *testing.T) {
+	seven := 7
+	v := normalized{
+		Int8:       -8,
+		Uint64:     math.MaxUint64,
+		Float32:    0.1,
+		Status:     "active",
+		Bytes:      []byte("abc"),
+		Array:      [2]byte{1, 2},
+		Time:       time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC),
+		IP:         net.IPv4(127, 0, 0, 1),
+		Pointer:    &seven,
+		Leaf:       leaf{Name: "n", Hidden: "h"},
+		IntKeys:    map[int]string{1: "one"},
+		Statuses:   map[status][]status{"a": {"b"}},
+		Nested:     []map[string]interface{}{{"x": uint8(1), "y": []float64{1e21, 1e-7}}},
+		Number:     "12.50",
+		Raw:        json.RawMessage(`{"a":[1]}`),
+		InvalidStr: "a\xffb",
+	}
+
+	mapper := mapify.Mapper{
+		ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+			return path == "", nil
+		},
+	}
+
+	for _, useNumber := range []bool{false, true} {
+		options := mapify.NormalizeOptions{UseNumber: useNumber}
+
+		t.Run(fmt.Sprintf("should return the same value as JSON round trip, UseNumber=%v", useNumber), func(t *testing.T) {
+			mapped, err := mapper.MapAny(v)
+			require.NoError(t, err)
+			// when
+			actual, err := mapper.MapNormalized(v, options)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, jsonRoundTrip(t, mapped, useNumber), actual)
+		})
+	}
+
+	t.Run("should convert leaves to JSON-native types", func(t *testing.T) {
+		actual, err := mapper.MapNormalized(v, mapify.NormalizeOptions{})
+		// then
+		require.NoError(t, err)
+		m := actual.(map[string]interface{})
+		assert.Equal(t, float64(-8), m["Int8"])
+		assert.Equal(t, 0.1, m["Float32"])
+		assert.Equal(t, "active", m["Status"])
+		assert.Equal(t, "YWJj", m["Bytes"])
+		assert.Nil(t, m["NilBytes"])
+		assert.Equal(t, []interface{}{float64(1), float64(2)}, m["Array"])
+		assert.Equal(t, map[string]interface{}{"name": "n"}, m["Leaf"])
+		assert.Equal(t, map[string]interface{}{"1": "one"}, m["IntKeys"])
+		assert.Equal(t, 12.5, m["Number"])
+	})
+
+	t.Run("should use json.Number", func(t *testing.T) {
+		actual, err := mapify.Normalize(map[string]interface{}{"a": uint64(math.MaxUint64), "b": float32(0.1)},
+			mapify.NormalizeOptions{UseNumber: true})
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{"a": json.Number("18446744073709551615"), "b": json.Number("0.1")}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should normalize scalar", func(t *testing.T) {
+		actual, err := mapify.Normalize(status("s"), mapify.NormalizeOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "s", actual)
+	})
+
+	t.Run("should return error for values not supported by JSON", func(t *testing.T) {
+		values := []interface{}{math.NaN(), make(chan int), map[string]interface{}{"a": math.Inf(1)}}
+
+		for _, value := range values {
+			_, err := mapify.Normalize(value, mapify.NormalizeOptions{})
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("should return error for cyclic map", func(t *testing.T) {
+		m := map[string]interface{}{}
+		m["self"] = m
+		// when
+		_, err := mapify.Normalize(m, mapify.NormalizeOptions{})
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func jsonRoundTrip(t *testing.T, v interface{}, useNumber bool) interface{} {
+	t.Helper()
+
+	encoded, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	if useNumber {
+		decoder.UseNumber()
+	}
+
+	var result interface{}
+	require.NoError(t, decoder.Decode(&result))
+
+	return result
+}
@@ -0,0 +1,52 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import "reflect"
+
+// Observer is notified by MapAny about mapping of each struct field, map entry and slice element. It can be used
+// to collect metrics, trace or log dropped fields without wrapping callbacks. When Mapper.Workers is greater
+// than one, Observer must be safe for concurrent use.
+//
+// Embed NoopObserver to implement only selected methods.
+type Observer interface {
+	// OnEnter is run before element at path is mapped.
+	OnEnter(path string)
+	// OnFiltered is run when element was rejected by Filter.
+	OnFiltered(path string)
+	// OnRenamed is run after Rename, also when the name was not changed.
+	OnRenamed(path, from, to string)
+	// OnMapped is run after MapValue.
+	OnMapped(path string)
+	// OnError is run when callback failed. err is the error which will be returned by MapAny.
+	OnError(path string, err error)
+	// OnExit is run after element at path was mapped, also when mapping failed.
+	OnExit(path string)
+}
+
+// NoopObserver is an Observer which does nothing.
+type NoopObserver struct{}
+
+func (NoopObserver) OnEnter(string)                   {}
+func (NoopObserver) OnFiltered(string)                {}
+func (NoopObserver) OnRenamed(string, string, string) {}
+func (NoopObserver) OnMapped(string)                  {}
+func (NoopObserver) OnError(string, error)            {}
+func (NoopObserver) OnExit(string)                    {}
+
+func (i Mapper) observeError(path string, err error) error {
+	i.Observer.OnError(path, err)
+
+	return err
+}
+
+// observed runs OnEnter and OnExit around mapping of slice element.
+func (i Mapper) observed(mapElement elementMapper) elementMapper {
+	return func(path string, reflectValue reflect.Value) (map[string]interface{}, error) {
+		i.Observer.OnEnter(path)
+		defer i.Observer.OnExit(path)
+
+		return mapElement(path, reflectValue)
+	}
+}
@@ -0,0 +1,171 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	events []string
+}
+
+func (o *recordingObserver) OnEnter(path string)    { o.record("enter %s", path) }
+func (o *recordingObserver) OnFiltered(path string) { o.record("filtered %s", path) }
+func (o *recordingObserver) OnRenamed(path, from, to string) {
+	o.record("renamed %s %s->%s", path, from, to)
+}
+func (o *recordingObserver) OnMapped(path string)           { o.record("mapped %s", path) }
+func (o *recordingObserver) OnError(path string, err error) { o.record("error %s: %s", path, err) }
+func (o *recordingObserver) OnExit(path string)             { o.record("exit %s", path) }
+
+func (o *recordingObserver) record(format string, args ...interface{}) {
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+type observed struct {
+	Name   string
+	Secret string
+	Items  []observedItem
+	Grid   [][]observedItem
+}
+
+type observedItem struct {
+	Price int
+}
+
+func TestMapper_Observer(t *testing.T) {
+	t.Run("should notify observer", func(t *testing.T) {
+		observer := &recordingObserver{}
+		mapper := mapify.Mapper{
+			Observer: observer,
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return e.Name() != "Secret", nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return strings.ToLower(e.Name()), nil
+			},
+		}
+		v := observed{Items: []observedItem{{}}, Grid: [][]observedItem{{{}}}}
+		// when
+		_, err := mapper.MapAny(v)
+		// then
+		require.NoError(t, err)
+		expected := []string{
+			"enter .Name", "renamed .Name Name->name", "mapped .Name", "exit .Name",
+			"enter .Secret", "filtered .Secret", "exit .Secret",
+			"enter .Items", "renamed .Items Items->items", "mapped .Items",
+			"enter .Items[0]",
+			"enter .Items[0].Price", "renamed .Items[0].Price Price->price", "mapped .Items[0].Price",
+			"exit .Items[0].Price",
+			"exit .Items[0]",
+			"exit .Items",
+			"enter .Grid", "renamed .Grid Grid->grid", "mapped .Grid",
+			"enter .Grid[0]",
+			"enter .Grid[0][0]",
+			"enter .Grid[0][0].Price", "renamed .Grid[0][0].Price Price->price", "mapped .Grid[0][0].Price",
+			"exit .Grid[0][0].Price",
+			"exit .Grid[0][0]",
+			"exit .Grid[0]",
+			"exit .Grid",
+		}
+		assert.Equal(t, expected, observer.events)
+	})
+
+	t.Run("should notify about errors once", func(t *testing.T) {
+		callbackError := errors.New("failed")
+
+		tests := map[string]struct {
+			mapper   mapify.Mapper
+			expected string
+		}{
+			"Filter": {
+				mapper: mapify.Mapper{
+					Filter: func(path string, e mapify.Element) (bool, error) {
+						return false, callbackError
+					},
+				},
+				expected: "error .Price: Filter failed: failed",
+			},
+			"Rename": {
+				mapper: mapify.Mapper{
+					Rename: func(path string, e mapify.Element) (string, error) {
+						return "", callbackError
+					},
+				},
+				expected: "error .Price: Rename failed: failed",
+			},
+			"MapValue": {
+				mapper: mapify.Mapper{
+					MapValue: func(path string, e mapify.Element) (interface{}, error) {
+						return nil, callbackError
+					},
+				},
+				expected: "error .Price: MapValue failed: failed",
+			},
+			"ShouldConvert": {
+				mapper: mapify.Mapper{
+					ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+						return false, callbackError
+					},
+				},
+				expected: "error : ShouldConvert failed: failed",
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				observer := &recordingObserver{}
+				test.mapper.Observer = observer
+				// when
+				_, err := test.mapper.MapAny(observedItem{})
+				// then
+				require.ErrorIs(t, err, callbackError)
+				var errorEvents []string
+				for _, event := range observer.events {
+					if strings.HasPrefix(event, "error") {
+						errorEvents = append(errorEvents, event)
+					}
+				}
+				assert.Equal(t, []string{test.expected}, errorEvents)
+			})
+		}
+	})
+
+	t.Run("should notify observer from multiple workers", func(t *testing.T) {
+		observer := &countingObserver{}
+		mapper := mapify.Mapper{Observer: observer, Workers: 4, ParallelThreshold: 10}
+		// when
+		_, err := mapper.MapAny(make([]observedItem, 100))
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(200), atomic.LoadInt64(&observer.mapped)+atomic.LoadInt64(&observer.entered))
+	})
+}
+
+type countingObserver struct {
+	mapify.NoopObserver
+	entered, mapped int64
+}
+
+func (o *countingObserver) OnEnter(path string) {
+	if !strings.HasSuffix(path, "]") {
+		return
+	}
+
+	atomic.AddInt64(&o.entered, 1)
+}
+
+func (o *countingObserver) OnMapped(string) {
+	atomic.AddInt64(&o.mapped, 1)
+}
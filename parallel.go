@@ -0,0 +1,113 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultParallelThreshold = 1000
+
+type elementMapper func(path string, reflectValue reflect.Value) (map[string]interface{}, error)
+
+// mapSliceElements maps each element of a slice using mapElement. Large slices are split into chunks mapped by
+// workers. Output order is preserved and the error returned is the one for the lowest index, same as
+// in sequential mapping.
+func (i Mapper) mapSliceElements(path string, reflectValue reflect.Value, mapElement elementMapper) (
+	[]map[string]interface{}, error) {
+
+	length := reflectValue.Len()
+	slice := make([]map[string]interface{}, length)
+
+	if i.semaphore == nil || length < i.ParallelThreshold {
+		for j := 0; j < length; j++ {
+			var err error
+
+			slice[j], err = mapElement(slicePath(path, j), reflectValue.Index(j))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return slice, nil
+	}
+
+	chunkSize := (length + i.Workers - 1) / i.Workers
+	chunks := (length + chunkSize - 1) / chunkSize
+
+	var (
+		wg          sync.WaitGroup
+		firstFailed = int64(length)
+		errs        = make([]error, chunks)
+		panics      = make([]interface{}, chunks)
+	)
+
+	mapChunk := func(chunk, from, to int) {
+		for j := from; j < to && int64(j) < atomic.LoadInt64(&firstFailed); j++ {
+			var err error
+
+			slice[j], err = mapElement(slicePath(path, j), reflectValue.Index(j))
+			if err != nil {
+				errs[chunk] = err
+				storeMin(&firstFailed, int64(j))
+
+				return
+			}
+		}
+	}
+
+	for chunk := 0; chunk < chunks; chunk++ {
+		from := chunk * chunkSize
+		to := from + chunkSize
+
+		if to > length {
+			to = length
+		}
+
+		select {
+		case i.semaphore <- struct{}{}:
+			wg.Add(1)
+
+			go func(chunk, from, to int) {
+				defer wg.Done()
+				defer func() { <-i.semaphore }()
+				defer func() {
+					if p := recover(); p != nil {
+						panics[chunk] = p
+						storeMin(&firstFailed, int64(from))
+					}
+				}()
+
+				mapChunk(chunk, from, to)
+			}(chunk, from, to)
+		default: // all workers are busy
+			mapChunk(chunk, from, to)
+		}
+	}
+
+	wg.Wait()
+
+	for chunk := range errs {
+		if panics[chunk] != nil {
+			panic(panics[chunk])
+		}
+
+		if errs[chunk] != nil {
+			return nil, errs[chunk]
+		}
+	}
+
+	return slice, nil
+}
+
+func storeMin(addr *int64, value int64) {
+	for {
+		current := atomic.LoadInt64(addr)
+		if value >= current || atomic.CompareAndSwapInt64(addr, current, value) {
+			return
+		}
+	}
+}
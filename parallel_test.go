@@ -0,0 +1,133 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapper_Workers(t *testing.T) {
+	type element struct{ Index int }
+
+	const length = 5000
+
+	structs := make([]element, length)
+	maps := make([]map[string]int, length)
+
+	for j := 0; j < length; j++ {
+		structs[j] = element{Index: j}
+		maps[j] = map[string]int{"Index": j}
+	}
+
+	tests := map[string]interface{}{
+		"struct": structs,
+		"map":    maps,
+	}
+
+	t.Run("should map slice preserving order", func(t *testing.T) {
+		for name, slice := range tests {
+			t.Run(name, func(t *testing.T) {
+				mapper := mapify.Mapper{Workers: 4}
+				// when
+				actual, err := mapper.MapAny(slice)
+				// then
+				require.NoError(t, err)
+				expected, err := mapify.Mapper{}.MapAny(slice)
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual)
+			})
+		}
+	})
+
+	t.Run("should return error for the lowest index", func(t *testing.T) {
+		for name, slice := range tests {
+			t.Run(name, func(t *testing.T) {
+				mapper := mapify.Mapper{
+					Workers:           8,
+					ParallelThreshold: 10,
+					Filter: func(path string, e mapify.Element) (bool, error) {
+						if index := int(e.Int()); index%1000 == 999 {
+							return false, stringError(strconv.Itoa(index))
+						}
+
+						return true, nil
+					},
+				}
+				// when
+				result, err := mapper.MapAny(slice)
+				// then
+				assert.Nil(t, result)
+				assert.ErrorIs(t, err, stringError("999"))
+			})
+		}
+	})
+
+	t.Run("should map elements concurrently", func(t *testing.T) {
+		var running, maxRunning int32
+
+		mapper := mapify.Mapper{
+			Workers:           4,
+			ParallelThreshold: 1,
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+
+				for {
+					highest := atomic.LoadInt32(&maxRunning)
+					if current <= highest || atomic.CompareAndSwapInt32(&maxRunning, highest, current) {
+						break
+					}
+				}
+
+				return e.Interface(), nil
+			},
+		}
+		// when
+		_, err := mapper.MapAny(structs)
+		// then
+		require.NoError(t, err)
+		assert.LessOrEqual(t, int(atomic.LoadInt32(&maxRunning)), 5, "at most 4 workers and calling goroutine")
+	})
+
+	t.Run("should map short slices sequentially", func(t *testing.T) {
+		var running int32
+
+		mapper := mapify.Mapper{
+			Workers: 4,
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				if atomic.AddInt32(&running, 1) > 1 {
+					return nil, fmt.Errorf("element mapped concurrently")
+				}
+				defer atomic.AddInt32(&running, -1)
+
+				return e.Interface(), nil
+			},
+		}
+		// when
+		_, err := mapper.MapAny(structs[:999])
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should propagate panic to calling goroutine", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			Workers:           4,
+			ParallelThreshold: 1,
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				panic("panic in MapValue")
+			},
+		}
+
+		assert.PanicsWithValue(t, "panic in MapValue", func() {
+			_, _ = mapper.MapAny(structs)
+		})
+	})
+}
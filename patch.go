@@ -0,0 +1,528 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSON Patch operations.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// PatchOperation is a single operation of JSON Patch (RFC 6902). Path and From are JSON Pointers (RFC 6901),
+// for example "/items/0/price".
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+type jsonPatchOperation struct {
+	Op    string       `json:"op"`
+	Path  string       `json:"path"`
+	From  string       `json:"from,omitempty"`
+	Value *interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON encodes the operation. Value is encoded for add, replace and test operations, also when it is nil.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	op := jsonPatchOperation{Op: o.Op, Path: o.Path, From: o.From}
+
+	if o.Op == OpAdd || o.Op == OpReplace || o.Op == OpTest {
+		op.Value = &o.Value
+	}
+
+	return json.Marshal(op)
+}
+
+// UnmarshalJSON decodes the operation.
+func (o *PatchOperation) UnmarshalJSON(data []byte) error {
+	var op jsonPatchOperation
+	if err := json.Unmarshal(data, &op); err != nil {
+		return err
+	}
+
+	*o = PatchOperation{Op: op.Op, Path: op.Path, From: op.From}
+	if op.Value != nil {
+		o.Value = *op.Value
+	}
+
+	return nil
+}
+
+// CreatePatch maps a and b using MapAny and creates JSON Patch using CreatePatchMapped.
+func (i Mapper) CreatePatch(a, b interface{}) ([]PatchOperation, error) {
+	mappedA, err := i.MapAny(a)
+	if err != nil {
+		return nil, err
+	}
+
+	mappedB, err := i.MapAny(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreatePatchMapped(mappedA, mappedB), nil
+}
+
+// CreatePatchMapped creates JSON Patch transforming a into b, which are values returned by MapAny or other nested
+// maps and slices. Maps with string keys and slices are compared recursively, and other values are compared using
+// reflect.DeepEqual. Nil maps and slices are compared as null values.
+//
+// Changed values are replaced. Slices are compared by index: elements are added or removed at the end. When
+// a value removed from a map is equal to a value added to a map, the value is moved.
+func CreatePatchMapped(a, b interface{}) []PatchOperation {
+	p := patchBuilder{}
+	p.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+
+	return p.operations()
+}
+
+type patchBuilder struct {
+	ops []PatchOperation
+	// removedKeys are indexes of remove operations for map keys, which can be converted to move operations.
+	removedKeys []int
+	// addedKeys are indexes of add operations for map keys.
+	addedKeys []int
+}
+
+func (p *patchBuilder) diff(pointer string, a, b reflect.Value) {
+	a, b = dereferenceInterface(a), dereferenceInterface(b)
+
+	switch {
+	case isNull(a) || isNull(b): // null is not equal to empty object or array
+		if isNull(a) != isNull(b) {
+			p.ops = append(p.ops, PatchOperation{Op: OpReplace, Path: pointer, Value: interfaceOrNil(b)})
+		}
+	case isStringMap(a) && isStringMap(b):
+		p.diffMaps(pointer, a, b)
+	case isSlice(a) && isSlice(b):
+		p.diffSlices(pointer, a, b)
+	default:
+		newValue := interfaceOrNil(b)
+
+		if !reflect.DeepEqual(interfaceOrNil(a), newValue) {
+			p.ops = append(p.ops, PatchOperation{Op: OpReplace, Path: pointer, Value: newValue})
+		}
+	}
+}
+
+func (p *patchBuilder) diffMaps(pointer string, a, b reflect.Value) {
+	keys := map[string]bool{}
+
+	for _, key := range a.MapKeys() {
+		keys[key.String()] = true
+	}
+
+	for _, key := range b.MapKeys() {
+		keys[key.String()] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		keyPointer := pointer + "/" + escapePointerToken(key)
+		oldValue := a.MapIndex(reflect.ValueOf(key).Convert(a.Type().Key()))
+		newValue := b.MapIndex(reflect.ValueOf(key).Convert(b.Type().Key()))
+
+		switch {
+		case !oldValue.IsValid():
+			p.addedKeys = append(p.addedKeys, len(p.ops))
+			p.ops = append(p.ops, PatchOperation{Op: OpAdd, Path: keyPointer, Value: newValue.Interface()})
+		case !newValue.IsValid():
+			p.removedKeys = append(p.removedKeys, len(p.ops))
+			p.ops = append(p.ops, PatchOperation{Op: OpRemove, Path: keyPointer, Value: oldValue.Interface()})
+		default:
+			p.diff(keyPointer, oldValue, newValue)
+		}
+	}
+}
+
+func (p *patchBuilder) diffSlices(pointer string, a, b reflect.Value) {
+	for j := 0; j < a.Len() && j < b.Len(); j++ {
+		p.diff(pointer+"/"+strconv.Itoa(j), a.Index(j), b.Index(j))
+	}
+
+	for j := a.Len(); j < b.Len(); j++ {
+		p.ops = append(p.ops, PatchOperation{
+			Op:    OpAdd,
+			Path:  pointer + "/" + strconv.Itoa(j),
+			Value: b.Index(j).Interface(),
+		})
+	}
+
+	for j := a.Len() - 1; j >= b.Len(); j-- {
+		p.ops = append(p.ops, PatchOperation{Op: OpRemove, Path: pointer + "/" + strconv.Itoa(j)})
+	}
+}
+
+// operations converts pairs of removed and added keys with equal values into move operations.
+func (p *patchBuilder) operations() []PatchOperation {
+	skipped := map[int]bool{}
+
+	for _, added := range p.addedKeys {
+		for _, removed := range p.removedKeys {
+			if !skipped[removed] && reflect.DeepEqual(p.ops[added].Value, p.ops[removed].Value) {
+				p.ops[added] = PatchOperation{Op: OpMove, From: p.ops[removed].Path, Path: p.ops[added].Path}
+				skipped[removed] = true
+
+				break
+			}
+		}
+	}
+
+	var ops []PatchOperation
+
+	for j, op := range p.ops {
+		if skipped[j] {
+			continue
+		}
+
+		if op.Op == OpRemove {
+			op.Value = nil // value was needed only for finding moves
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops
+}
+
+// ApplyPatch applies JSON Patch to a copy of doc and returns the copy. Maps with string keys and slices are
+// copied into map[string]interface{} and []interface{}. Operations are applied atomically: when an operation
+// fails, error is returned and doc is not modified. Values are compared by test operation as JSON values,
+// so for example int(1) is equal to float64(1).
+func ApplyPatch(doc map[string]interface{}, patch []PatchOperation) (map[string]interface{}, error) {
+	var result interface{} = copyContainers(reflect.ValueOf(doc))
+
+	for j, op := range patch {
+		var err error
+
+		result, err = applyOperation(result, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s) failed: %w", j, op.Op, op.Path, err)
+		}
+	}
+
+	m, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patched document is not an object")
+	}
+
+	return m, nil
+}
+
+func applyOperation(doc interface{}, op PatchOperation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return addValue(doc, path, copyContainers(reflect.ValueOf(op.Value)))
+	case OpRemove:
+		doc, _, err = removeValue(doc, path)
+
+		return doc, err
+	case OpReplace:
+		if _, err = getValue(doc, path); err != nil {
+			return nil, err
+		}
+
+		if len(path) == 0 {
+			return copyContainers(reflect.ValueOf(op.Value)), nil
+		}
+
+		doc, _, err = removeValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return addValue(doc, path, copyContainers(reflect.ValueOf(op.Value)))
+	case OpMove, OpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == OpMove {
+			if isPointerPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("value cannot be moved into one of its children")
+			}
+
+			var value interface{}
+
+			doc, value, err = removeValue(doc, from)
+			if err != nil {
+				return nil, err
+			}
+
+			return addValue(doc, path, value)
+		}
+
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return addValue(doc, path, copyContainers(reflect.ValueOf(value)))
+	case OpTest:
+		value, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return doc, testValue(value, op.Value)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+func testValue(actual, expected interface{}) error {
+	normalizedActual, err := Normalize(actual, NormalizeOptions{})
+	if err != nil {
+		return err
+	}
+
+	normalizedExpected, err := Normalize(expected, NormalizeOptions{})
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(normalizedActual, normalizedExpected) {
+		return errors.New("test failed")
+	}
+
+	return nil
+}
+
+func isNull(value reflect.Value) bool {
+	return !value.IsValid() || isNil(value)
+}
+
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON Pointer %q must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for j, token := range tokens {
+		tokens[j] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
+
+func isPointerPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for j := range prefix {
+		if prefix[j] != path[j] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", token)
+			}
+
+			doc = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = container[index]
+		default:
+			return nil, fmt.Errorf("%q cannot be found in %T", token, doc)
+		}
+	}
+
+	return doc, nil
+}
+
+// modifyParent runs modify with the container of value at path and returns updated doc.
+func modifyParent(doc interface{}, path []string,
+	modify func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+
+	if len(path) == 1 {
+		return modify(doc, path[0])
+	}
+
+	child, err := getValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := modifyParent(child, path[1:], modify)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		container[path[0]] = updated
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(container)-1)
+		container[index] = updated
+	}
+
+	return doc, nil
+}
+
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modifyParent(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, value), nil
+			}
+
+			index, err := arrayIndex(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+
+			c = append(c, nil)
+			copy(c[index+1:], c[index:])
+			c[index] = value
+
+			return c, nil
+		default:
+			return nil, fmt.Errorf("value cannot be added to %T", container)
+		}
+	})
+}
+
+func removeValue(doc interface{}, path []string) (_ interface{}, removed interface{}, _ error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("root cannot be removed")
+	}
+
+	doc, err := modifyParent(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			value, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", token)
+			}
+
+			removed = value
+			delete(c, token)
+
+			return c, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			removed = c[index]
+
+			return append(c[:index], c[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%q cannot be removed from %T", token, container)
+		}
+	})
+
+	return doc, removed, err
+}
+
+// arrayIndex parses array index, which must not be greater than max.
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') || token[0] == '+' {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+
+	return index, nil
+}
+
+// copyContainers deeply copies maps with string keys into map[string]interface{} and slices into []interface{}.
+// Other values are returned as-is.
+func copyContainers(value reflect.Value) interface{} {
+	value = dereferenceInterface(value)
+
+	switch {
+	case !value.IsValid():
+		return nil
+	case isStringMap(value):
+		if value.IsNil() {
+			return nil
+		}
+
+		m := make(map[string]interface{}, value.Len())
+
+		iter := value.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = copyContainers(iter.Value())
+		}
+
+		return m
+	case isSlice(value):
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+
+		s := make([]interface{}, value.Len())
+		for j := range s {
+			s[j] = copyContainers(value.Index(j))
+		}
+
+		return s
+	default:
+		return value.Interface()
+	}
+}
@@ -0,0 +1,208 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapper_CreatePatch(t *testing.T) {
+	old := auditedOrder{ID: 1, Status: "new", Items: []auditedItem{{SKU: "a", Qty: 1}, {SKU: "b", Qty: 2}}}
+	updated := auditedOrder{ID: 1, Status: "paid", Items: []auditedItem{{SKU: "a", Qty: 5}}, Tags: []string{"x"}}
+
+	t.Run("should create patch", func(t *testing.T) {
+		patch, err := mapify.Mapper{Rename: lowerRename}.CreatePatch(old, updated)
+		// then
+		require.NoError(t, err)
+		expected := []mapify.PatchOperation{
+			{Op: mapify.OpReplace, Path: "/items/0/qty", Value: 5},
+			{Op: mapify.OpRemove, Path: "/items/1"},
+			{Op: mapify.OpReplace, Path: "/status", Value: "paid"},
+			{Op: mapify.OpReplace, Path: "/tags", Value: []string{"x"}},
+		}
+		assert.Equal(t, expected, patch)
+	})
+
+	t.Run("applied patch should transform old into new", func(t *testing.T) {
+		mapper := mapify.Mapper{Rename: lowerRename}
+		patch, err := mapper.CreatePatch(old, updated)
+		require.NoError(t, err)
+		oldMapped, err := mapper.MapAny(old)
+		require.NoError(t, err)
+		newMapped, err := mapper.MapAny(updated)
+		require.NoError(t, err)
+		// when
+		actual, err := mapify.ApplyPatch(oldMapped.(map[string]interface{}), patch)
+		// then
+		require.NoError(t, err)
+		changes, err := mapify.DiffMapped(newMapped, actual, mapify.DiffOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestCreatePatchMapped(t *testing.T) {
+	t.Run("should move value", func(t *testing.T) {
+		a := map[string]interface{}{"old": map[string]interface{}{"x": 1}, "a/b": "~"}
+		b := map[string]interface{}{"nested": map[string]interface{}{"new": map[string]interface{}{"x": 1}}, "a/b": "~"}
+		// when
+		patch := mapify.CreatePatchMapped(a, b)
+		// then
+		expected := []mapify.PatchOperation{
+			{Op: mapify.OpAdd, Path: "/nested", Value: map[string]interface{}{"new": map[string]interface{}{"x": 1}}},
+			{Op: mapify.OpRemove, Path: "/old"},
+		}
+		assert.Equal(t, expected, patch)
+	})
+
+	t.Run("should move value between keys and escape pointer", func(t *testing.T) {
+		a := map[string]interface{}{"a/b": 1, "c": 2}
+		b := map[string]interface{}{"c~d": 1, "c": 2}
+		// when
+		patch := mapify.CreatePatchMapped(a, b)
+		// then
+		expected := []mapify.PatchOperation{{Op: mapify.OpMove, From: "/a~1b", Path: "/c~0d"}}
+		assert.Equal(t, expected, patch)
+	})
+
+	t.Run("should add slice elements", func(t *testing.T) {
+		patch := mapify.CreatePatchMapped([]int{1}, []int{1, 2, 3})
+		// then
+		expected := []mapify.PatchOperation{
+			{Op: mapify.OpAdd, Path: "/1", Value: 2},
+			{Op: mapify.OpAdd, Path: "/2", Value: 3},
+		}
+		assert.Equal(t, expected, patch)
+	})
+
+	t.Run("should return no operations for equal values", func(t *testing.T) {
+		v := map[string]interface{}{"a": []int{1}, "b": nil, "c": map[string]int{}}
+		patch := mapify.CreatePatchMapped(v, v)
+		assert.Empty(t, patch)
+	})
+
+	t.Run("should replace empty slice with null", func(t *testing.T) {
+		patch := mapify.CreatePatchMapped(map[string]interface{}{"a": []int{}}, map[string]interface{}{"a": []int(nil)})
+		assert.Equal(t, []mapify.PatchOperation{{Op: mapify.OpReplace, Path: "/a", Value: []int(nil)}}, patch)
+	})
+}
+
+func TestApplyPatch(t *testing.T) {
+	doc := func() map[string]interface{} {
+		return map[string]interface{}{
+			"a":   map[string]interface{}{"b": []map[string]interface{}{{"c": 1}}},
+			"x/y": "z",
+			"arr": []string{"0", "1", "2"},
+		}
+	}
+
+	t.Run("should apply operations", func(t *testing.T) {
+		tests := map[string]struct {
+			patch    string
+			expected string
+		}{
+			"add to object": {
+				patch:    `[{"op": "add", "path": "/new", "value": null}]`,
+				expected: `{"a": {"b": [{"c": 1}]}, "x/y": "z", "arr": ["0", "1", "2"], "new": null}`,
+			},
+			"add to array": {
+				patch:    `[{"op": "add", "path": "/arr/1", "value": "n"}, {"op": "add", "path": "/arr/-", "value": "e"}]`,
+				expected: `{"a": {"b": [{"c": 1}]}, "x/y": "z", "arr": ["0", "n", "1", "2", "e"]}`,
+			},
+			"remove": {
+				patch:    `[{"op": "remove", "path": "/arr/0"}, {"op": "remove", "path": "/x~1y"}]`,
+				expected: `{"a": {"b": [{"c": 1}]}, "arr": ["1", "2"]}`,
+			},
+			"replace": {
+				patch:    `[{"op": "replace", "path": "/a/b/0/c", "value": 2}]`,
+				expected: `{"a": {"b": [{"c": 2}]}, "x/y": "z", "arr": ["0", "1", "2"]}`,
+			},
+			"replace root": {
+				patch:    `[{"op": "replace", "path": "", "value": {"r": 1}}]`,
+				expected: `{"r": 1}`,
+			},
+			"move": {
+				patch:    `[{"op": "move", "from": "/a/b/0", "path": "/moved"}]`,
+				expected: `{"a": {"b": []}, "x/y": "z", "arr": ["0", "1", "2"], "moved": {"c": 1}}`,
+			},
+			"copy": {
+				patch:    `[{"op": "copy", "from": "/arr", "path": "/copied"}, {"op": "remove", "path": "/copied/0"}]`,
+				expected: `{"a": {"b": [{"c": 1}]}, "x/y": "z", "arr": ["0", "1", "2"], "copied": ["1", "2"]}`,
+			},
+			"test": {
+				patch:    `[{"op": "test", "path": "/a", "value": {"b": [{"c": 1.0}]}}]`,
+				expected: `{"a": {"b": [{"c": 1}]}, "x/y": "z", "arr": ["0", "1", "2"]}`,
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				var patch []mapify.PatchOperation
+				require.NoError(t, json.Unmarshal([]byte(test.patch), &patch))
+				// when
+				actual, err := mapify.ApplyPatch(doc(), patch)
+				// then
+				require.NoError(t, err)
+				assertJSON(t, test.expected, actual)
+			})
+		}
+	})
+
+	t.Run("should return error", func(t *testing.T) {
+		tests := map[string]string{
+			"missing key":          `[{"op": "remove", "path": "/missing"}]`,
+			"index out of bounds":  `[{"op": "add", "path": "/arr/4", "value": 1}]`,
+			"leading zero":         `[{"op": "replace", "path": "/arr/01", "value": 1}]`,
+			"invalid pointer":      `[{"op": "add", "path": "a", "value": 1}]`,
+			"test failed":          `[{"op": "test", "path": "/x~1y", "value": "other"}]`,
+			"move into child":      `[{"op": "move", "from": "/a", "path": "/a/child"}]`,
+			"unknown operation":    `[{"op": "unknown", "path": "/a"}]`,
+			"remove root":          `[{"op": "remove", "path": ""}]`,
+			"replace missing":      `[{"op": "replace", "path": "/missing", "value": 1}]`,
+			"root is not object":   `[{"op": "replace", "path": "", "value": 1}]`,
+			"add to scalar":        `[{"op": "add", "path": "/x~1y/z", "value": 1}]`,
+			"later operation fail": `[{"op": "remove", "path": "/arr"}, {"op": "remove", "path": "/arr"}]`,
+		}
+
+		for name, patchJSON := range tests {
+			t.Run(name, func(t *testing.T) {
+				var patch []mapify.PatchOperation
+				require.NoError(t, json.Unmarshal([]byte(patchJSON), &patch))
+				original := doc()
+				// when
+				_, err := mapify.ApplyPatch(original, patch)
+				// then
+				assert.Error(t, err)
+				assert.Equal(t, doc(), original, "original document must not be modified")
+			})
+		}
+	})
+}
+
+func TestPatchOperation_MarshalJSON(t *testing.T) {
+	patch := []mapify.PatchOperation{
+		{Op: mapify.OpAdd, Path: "/a", Value: nil},
+		{Op: mapify.OpRemove, Path: "/b"},
+		{Op: mapify.OpMove, From: "/c", Path: "/d"},
+	}
+	// when
+	actual, err := json.Marshal(patch)
+	// then
+	require.NoError(t, err)
+	expected := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"op":"move","path":"/d","from":"/c"}]`
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func assertJSON(t *testing.T, expected string, actual interface{}) {
+	t.Helper()
+
+	encoded, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(encoded))
+}
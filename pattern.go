@@ -0,0 +1,100 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pathPattern matches paths in MapAny syntax, such as ".Items[0].Price". In patterns "*" matches any field
+// or key, "[*]" matches any index and "**" matches any number of segments, for example ".**.Password".
+type pathPattern []string
+
+func parsePathPattern(pattern string) (pathPattern, error) {
+	segments, err := splitPath(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+	}
+
+	return segments, nil
+}
+
+func (p pathPattern) match(path string) bool {
+	segments, err := splitPath(path)
+	if err != nil {
+		return false
+	}
+
+	return matchSegments(p, segments)
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for j := 0; j <= len(segments); j++ {
+				if matchSegments(pattern[1:], segments[j:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(segments) == 0 || !matchSegment(pattern[0], segments[0]) {
+			return false
+		}
+
+		pattern, segments = pattern[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
+
+func matchSegment(pattern, segment string) bool {
+	isIndex := strings.HasPrefix(segment, "[")
+
+	switch pattern {
+	case "*":
+		return !isIndex
+	case "[*]":
+		return isIndex
+	default:
+		return pattern == segment
+	}
+}
+
+// splitPath splits path into segments: ".Items[0].Price" is split into "Items", "[0]" and "Price".
+func splitPath(path string) ([]string, error) {
+	var segments []string
+
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+
+			if end == 0 {
+				return nil, fmt.Errorf("empty name")
+			}
+
+			segments = append(segments, path[1:end+1])
+			path = path[end+1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ]")
+			}
+
+			segments = append(segments, path[:end+1])
+			path = path[end+1:]
+		default:
+			return nil, fmt.Errorf("segment must start with . or [")
+		}
+	}
+
+	return segments, nil
+}
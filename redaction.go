@@ -0,0 +1,264 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Mask replaces sensitive value with a value which can be safely logged.
+type Mask func(value interface{}) (interface{}, error)
+
+// DefaultMask is used when Redaction.Default is nil.
+var DefaultMask = FixedMask("[REDACTED]")
+
+// Redaction specifies which elements are sensitive and how they are masked. Element is sensitive when its struct
+// field has Tag, its path matches one of Paths or its type (declared or dynamic) is one of Types. Elements of
+// slices and arrays are checked using Paths and Types too, for example ".Cards[*]".
+type Redaction struct {
+	// Tag is the name of struct tag marking sensitive fields. Tag value is the name of the mask in Masks, or empty
+	// for the Default mask. For example, with Tag "redact" use `redact:""` or `redact:"email"`.
+	Tag string
+	// Masks are masks which can be selected by tag value.
+	Masks map[string]Mask
+	// Paths maps path patterns to masks. Patterns use MapAny path syntax, where "*" matches any field or key,
+	// "[*]" matches any index and "**" matches any number of segments, for example ".**.Password".
+	// Nil mask means the Default mask.
+	Paths map[string]Mask
+	// Types maps types of sensitive values to masks. Nil mask means the Default mask.
+	Types map[reflect.Type]Mask
+	// Default mask. Nil means DefaultMask.
+	Default Mask
+}
+
+// WithRedaction returns a copy of Mapper which masks sensitive elements. Sensitive values are replaced before
+// any callback is run, so Filter, Rename and MapValue receive only masked values, and masked values are never
+// traversed further. Note that callbacks run for parent elements (such as ShouldConvert for the struct
+// containing sensitive field) still receive the original parent value. Slices and arrays containing sensitive
+// elements are replaced with []interface{}, where other elements are already mapped. When Mask fails, the whole
+// conversion is aborted.
+//
+// Error is returned when a path pattern is invalid.
+func (i Mapper) WithRedaction(r Redaction) (Mapper, error) {
+	redactor := redactor{Redaction: r}
+	if redactor.Default == nil {
+		redactor.Default = DefaultMask
+	}
+
+	for pattern, mask := range r.Paths {
+		parsed, err := parsePathPattern(pattern)
+		if err != nil {
+			return Mapper{}, err
+		}
+
+		redactor.paths = append(redactor.paths, redactedPath{pattern: parsed, mask: mask})
+	}
+
+	prepare := i.prepareElement
+	i.prepareElement = func(instance Mapper, path string, e Element) (Element, error) {
+		if prepare != nil {
+			var err error
+			if e, err = prepare(instance, path, e); err != nil {
+				return e, err
+			}
+		}
+
+		r := redactor
+		r.mapAny = instance.mapAny
+
+		return r.redact(path, e)
+	}
+
+	return i, nil
+}
+
+type redactor struct {
+	Redaction
+	paths  []redactedPath
+	mapAny func(path string, v interface{}) (interface{}, error)
+}
+
+type redactedPath struct {
+	pattern pathPattern
+	mask    Mask
+}
+
+// redact returns element with masked value when the element is sensitive.
+func (r redactor) redact(path string, e Element) (Element, error) {
+	value, _, err := r.redactValue(path, e)
+	if err != nil {
+		return e, err
+	}
+
+	e.Value = value
+
+	return e, nil
+}
+
+// redactValue returns masked value of the element. changed is false when the element is not sensitive and
+// does not contain sensitive elements.
+func (r redactor) redactValue(path string, e Element) (_ reflect.Value, changed bool, _ error) {
+	mask, sensitive, err := r.mask(path, e)
+	if err != nil {
+		return e.Value, false, err
+	}
+
+	if !sensitive {
+		return r.redactElements(path, e.Value)
+	}
+
+	if mask == nil {
+		mask = r.Default
+	}
+
+	masked, err := mask(e.Interface())
+	if err != nil {
+		return e.Value, false, fmt.Errorf("masking %s failed: %w", path, err)
+	}
+
+	value := reflect.ValueOf(masked)
+	if !value.IsValid() {
+		value = reflect.ValueOf(&masked).Elem() // nil interface
+	}
+
+	return value, true, nil
+}
+
+// redactElements masks sensitive elements of slices and arrays, because MapAny does not run callbacks for them.
+// When some elements are masked, []interface{} is returned and other elements are mapped using MapAny, so their
+// nested sensitive elements are masked too.
+func (r redactor) redactElements(path string, value reflect.Value) (_ reflect.Value, changed bool, _ error) {
+	slice := dereferenceInterface(value)
+	if !isSlice(slice) || (len(r.paths) == 0 && len(r.Types) == 0) {
+		return value, false, nil
+	}
+
+	masked := map[int]reflect.Value{}
+
+	for j := 0; j < slice.Len(); j++ {
+		element, elementChanged, err := r.redactValue(slicePath(path, j), Element{Value: slice.Index(j)})
+		if err != nil {
+			return value, false, err
+		}
+
+		if elementChanged {
+			masked[j] = element
+		}
+	}
+
+	if len(masked) == 0 {
+		return value, false, nil
+	}
+
+	result := make([]interface{}, slice.Len())
+
+	for j := range result {
+		if element, ok := masked[j]; ok {
+			result[j] = element.Interface()
+			continue
+		}
+
+		mapped, err := r.mapAny(slicePath(path, j), slice.Index(j).Interface())
+		if err != nil {
+			return value, false, err
+		}
+
+		result[j] = mapped
+	}
+
+	return reflect.ValueOf(result), true, nil
+}
+
+func (r redactor) mask(path string, e Element) (_ Mask, sensitive bool, _ error) {
+	if field, ok := e.StructField(); ok && r.Tag != "" {
+		if name, found := field.Tag.Lookup(r.Tag); found {
+			if name == "" {
+				return nil, true, nil
+			}
+
+			mask, ok := r.Masks[name]
+			if !ok {
+				return nil, false, fmt.Errorf("unknown mask %q in tag of %s", name, path)
+			}
+
+			return mask, true, nil
+		}
+	}
+
+	for _, p := range r.paths {
+		if p.pattern.match(path) {
+			return p.mask, true, nil
+		}
+	}
+
+	if mask, ok := r.Types[e.Type()]; ok {
+		return mask, true, nil
+	}
+
+	if e.Kind() == reflect.Interface && !e.IsNil() {
+		if mask, ok := r.Types[e.Elem().Type()]; ok {
+			return mask, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// FixedMask replaces every value, including nil, with s.
+func FixedMask(s string) Mask {
+	return func(interface{}) (interface{}, error) {
+		return s, nil
+	}
+}
+
+// PartialMask formats value using FormatValue and replaces all characters with "*" except first keepFirst and
+// last keepLast characters, for example PartialMask(1, 4) masks "4111111111111111" as "4***********1111".
+// When the value is too short, all characters are replaced. Nil values are not masked.
+func PartialMask(keepFirst, keepLast int) Mask {
+	return func(value interface{}) (interface{}, error) {
+		if value == nil || isNil(reflect.ValueOf(value)) {
+			return nil, nil
+		}
+
+		s, err := FormatValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		runes := []rune(s)
+		if len(runes) <= keepFirst+keepLast {
+			return strings.Repeat("*", len(runes)), nil
+		}
+
+		hidden := len(runes) - keepFirst - keepLast
+
+		return string(runes[:keepFirst]) + strings.Repeat("*", hidden) + string(runes[keepFirst+hidden:]), nil
+	}
+}
+
+// HashMask formats value using FormatValue and replaces it with hex-encoded HMAC-SHA256 using salt as a key.
+// The same values give the same hashes, so they can be correlated without being revealed. Nil values are
+// not masked.
+func HashMask(salt []byte) Mask {
+	return func(value interface{}) (interface{}, error) {
+		if value == nil || isNil(reflect.ValueOf(value)) {
+			return nil, nil
+		}
+
+		s, err := FormatValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		h := hmac.New(sha256.New, salt)
+		_, _ = h.Write([]byte(s))
+
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+}
@@ -0,0 +1,325 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type token string
+
+type account struct {
+	Login       string
+	Password    string `redact:""`
+	Email       string `redact:"email"`
+	Token       token
+	Credentials credentials
+	Sessions    []session
+	Note        *string `redact:"hash"`
+}
+
+type credentials struct {
+	Key string
+}
+
+type session struct {
+	ID string
+}
+
+func TestMapper_WithRedaction(t *testing.T) {
+	redaction := mapify.Redaction{
+		Tag: "redact",
+		Masks: map[string]mapify.Mask{
+			"email": mapify.PartialMask(2, 4),
+			"hash":  mapify.HashMask([]byte("salt")),
+		},
+		Paths: map[string]mapify.Mask{
+			".Credentials":    nil,
+			".Sessions[*].ID": mapify.FixedMask("***"),
+		},
+		Types: map[reflect.Type]mapify.Mask{
+			reflect.TypeOf(token("")): mapify.FixedMask("<token>"),
+		},
+	}
+
+	v := account{
+		Login:       "john",
+		Password:    "secret",
+		Email:       "john@example.com",
+		Token:       "abc",
+		Credentials: credentials{Key: "key"},
+		Sessions:    []session{{ID: "1"}, {ID: "2"}},
+	}
+
+	t.Run("should mask sensitive values", func(t *testing.T) {
+		mapper, err := mapify.Mapper{}.WithRedaction(redaction)
+		require.NoError(t, err)
+		// when
+		actual, err := mapper.MapAny(v)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"Login":       "john",
+			"Password":    "[REDACTED]",
+			"Email":       "jo**********.com",
+			"Token":       "<token>",
+			"Credentials": "[REDACTED]",
+			"Sessions":    []map[string]interface{}{{"ID": "***"}, {"ID": "***"}},
+			"Note":        nil,
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should never pass sensitive values to callbacks", func(t *testing.T) {
+		var seen []interface{}
+
+		record := func(e mapify.Element) {
+			seen = append(seen, e.Interface())
+		}
+
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				record(e)
+				return true, nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				record(e)
+				return e.Name(), nil
+			},
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				record(e)
+				return e.Interface(), nil
+			},
+		}
+		mapper, err := mapper.WithRedaction(redaction)
+		require.NoError(t, err)
+		// when
+		_, err = mapper.MapAny(v)
+		// then
+		require.NoError(t, err)
+		for _, value := range seen {
+			assert.NotContains(t, []interface{}{"secret", "john@example.com", token("abc"), "key", "1", "2"}, value)
+			assert.NotEqual(t, v.Credentials, value)
+		}
+	})
+
+	t.Run("should mask values of map with dynamic type", func(t *testing.T) {
+		mapper, err := mapify.Mapper{}.WithRedaction(mapify.Redaction{
+			Paths:   map[string]mapify.Mask{".**.password": nil},
+			Types:   map[reflect.Type]mapify.Mask{reflect.TypeOf(token("")): nil},
+			Default: mapify.FixedMask("x"),
+		})
+		require.NoError(t, err)
+		v := map[string]interface{}{
+			"a":    token("t"),
+			"b":    map[string]interface{}{"c": map[string]string{"password": "p"}},
+			"name": "n",
+		}
+		// when
+		actual, err := mapper.MapAny(v)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"a":    "x",
+			"b":    map[string]interface{}{"c": map[string]interface{}{"password": "x"}},
+			"name": "n",
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should mask elements of slices", func(t *testing.T) {
+		mapper, err := mapify.Mapper{}.WithRedaction(mapify.Redaction{
+			Tag:   "redact",
+			Masks: redaction.Masks,
+			Paths: map[string]mapify.Mask{
+				".Cards[*]":    mapify.PartialMask(0, 4),
+				".Accounts[1]": nil,
+			},
+			Types: map[reflect.Type]mapify.Mask{reflect.TypeOf(token("")): nil},
+		})
+		require.NoError(t, err)
+		v := struct {
+			Tokens   []token
+			Matrix   [][]token
+			Mixed    []interface{}
+			Cards    []string
+			Accounts []account
+			Logins   []string
+		}{
+			Tokens:   []token{"s1", "s2"},
+			Matrix:   [][]token{{"s3"}},
+			Mixed:    []interface{}{"plain", token("s4")},
+			Cards:    []string{"4111111111111111"},
+			Accounts: []account{{Login: "john", Password: "secret"}, {Login: "jane"}},
+			Logins:   []string{"john"},
+		}
+		// when
+		actual, err := mapper.MapAny(v)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"Tokens": []interface{}{"[REDACTED]", "[REDACTED]"},
+			"Matrix": []interface{}{[]interface{}{"[REDACTED]"}},
+			"Mixed":  []interface{}{"plain", "[REDACTED]"},
+			"Cards":  []interface{}{"************1111"},
+			"Accounts": []interface{}{
+				map[string]interface{}{
+					"Login":       "john",
+					"Password":    "[REDACTED]",
+					"Email":       "",
+					"Token":       "[REDACTED]",
+					"Credentials": map[string]interface{}{"Key": ""},
+					"Sessions":    []map[string]interface{}{},
+					"Note":        nil,
+				},
+				"[REDACTED]",
+			},
+			"Logins": []string{"john"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return error for unknown mask in tag", func(t *testing.T) {
+		mapper, err := mapify.Mapper{}.WithRedaction(mapify.Redaction{Tag: "redact"})
+		require.NoError(t, err)
+		// when
+		_, err = mapper.MapAny(v)
+		// then
+		assert.ErrorContains(t, err, `unknown mask "email"`)
+	})
+
+	t.Run("should mask each element once", func(t *testing.T) {
+		calls := map[string]int{}
+
+		count := func(name string) {
+			calls[name]++
+		}
+
+		mapper, err := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				count("Filter" + path)
+				return true, nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				count("Rename" + path)
+				return e.Name(), nil
+			},
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				count("MapValue" + path)
+				return e.Interface(), nil
+			},
+		}.WithRedaction(mapify.Redaction{
+			Paths: map[string]mapify.Mask{".Sessions[0]": nil},
+			Default: func(value interface{}) (interface{}, error) {
+				count("Mask")
+				return "x", nil
+			},
+		})
+		require.NoError(t, err)
+		// when
+		actual, err := mapper.MapAny(struct{ Sessions []session }{
+			Sessions: []session{{ID: "1"}, {ID: "2"}},
+		})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"Sessions": []interface{}{"x", map[string]interface{}{"ID": "2"}},
+		}, actual)
+		expected := map[string]int{
+			"Mask":                    1,
+			"Filter.Sessions":         1,
+			"Rename.Sessions":         1,
+			"MapValue.Sessions":       1,
+			"Filter.Sessions[1].ID":   1,
+			"Rename.Sessions[1].ID":   1,
+			"MapValue.Sessions[1].ID": 1,
+		}
+		assert.Equal(t, expected, calls)
+	})
+
+	t.Run("should mask values encoded as JSON", func(t *testing.T) {
+		mapper, err := mapify.Mapper{}.WithRedaction(mapify.Redaction{Tag: "redact"})
+		require.NoError(t, err)
+		var out bytes.Buffer
+		// when
+		err = mapper.EncodeJSON(&out, struct {
+			Login    string
+			Password string `redact:""`
+		}{Login: "john", Password: "secret"})
+		// then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Login":"john","Password":"[REDACTED]"}`, out.String())
+	})
+
+	t.Run("should return error when mask failed", func(t *testing.T) {
+		maskError := errors.New("mask failed")
+		mapper, err := mapify.Mapper{}.WithRedaction(mapify.Redaction{
+			Tag: "redact",
+			Default: func(value interface{}) (interface{}, error) {
+				return nil, maskError
+			},
+		})
+		require.NoError(t, err)
+		// when
+		_, err = mapper.MapAny(struct {
+			Password string `redact:""`
+		}{})
+		// then
+		assert.ErrorIs(t, err, maskError)
+	})
+
+	t.Run("should return error for invalid path pattern", func(t *testing.T) {
+		_, err := mapify.Mapper{}.WithRedaction(mapify.Redaction{Paths: map[string]mapify.Mask{"Password": nil}})
+		assert.Error(t, err)
+	})
+}
+
+func TestMasks(t *testing.T) {
+	t.Run("PartialMask", func(t *testing.T) {
+		mask := mapify.PartialMask(1, 4)
+
+		tests := map[interface{}]interface{}{
+			"4111111111111111": "4***********1111",
+			"short":            "*****",
+			"żółw-żółw":        "ż****żółw",
+			12345678:           "1***5678",
+			nil:                nil,
+		}
+
+		for value, expected := range tests {
+			actual, err := mask(value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("HashMask", func(t *testing.T) {
+		mask := mapify.HashMask([]byte("salt"))
+		// when
+		first, err := mask("value")
+		require.NoError(t, err)
+		second, err := mask("value")
+		require.NoError(t, err)
+		other, err := mapify.HashMask([]byte("other"))("value")
+		require.NoError(t, err)
+		// then
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first, other)
+		assert.Len(t, first, 64)
+		assert.NotContains(t, first, "value")
+	})
+
+	t.Run("FixedMask", func(t *testing.T) {
+		actual, err := mapify.FixedMask("***")(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "***", actual)
+	})
+}
@@ -0,0 +1,175 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// ScanMaps reads all remaining rows into maps keyed by column names. Rows are not closed.
+func ScanMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+
+		for j := range values {
+			dest[j] = &values[j]
+		}
+
+		if err = rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scanning row %d failed: %w", len(result), err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for j, column := range columns {
+			row[column] = values[j]
+		}
+
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// ScanStructs reads all remaining rows into the slice pointed to by target. Slice elements must be structs
+// or pointers to structs. Rows are not closed.
+//
+// Columns are matched with struct fields using the same rules as MapColumns, but in reverse: the column name
+// of each field is computed by Filter and Rename (run with zero value of the field), and fields of nested structs
+// are prefixed with the name of the struct and options.Separator. So when Rename converts CreatedAt to
+// "created_at", the column "created_at" is scanned into CreatedAt. Columns not matching any field are ignored.
+// Pointers to nested structs are allocated only when at least one of their columns is not NULL.
+func (i Mapper) ScanStructs(rows *sql.Rows, target interface{}, options ColumnsOptions) error {
+	slice := reflect.ValueOf(target)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("target must be a non-nil pointer to slice, got %T", target)
+	}
+
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("slice elements must be structs or pointers to structs, got %s", elemType)
+	}
+
+	if options.Separator == "" {
+		options.Separator = "_"
+	}
+
+	instance := i.newInstance()
+
+	shouldConvert := instance.ShouldConvert
+	instance.ShouldConvert = func(path string, value reflect.Value) (bool, error) {
+		t := value.Type()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if isColumnValue(t) || reflect.PtrTo(t).Implements(scannerType) {
+			return false, nil
+		}
+
+		return shouldConvert(path, value)
+	}
+
+	structColumns, err := instance.structColumns(structType, "", options)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string][]int{}
+	for _, column := range structColumns {
+		fields[column.name] = column.index
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for row := 0; rows.Next(); row++ {
+		elem := reflect.New(structType)
+		dest := make([]interface{}, len(columns))
+
+		var pointed []int // indexes of columns which fields are set after scanning
+
+		for j, column := range columns {
+			index, ok := fields[column]
+
+			switch {
+			case !ok:
+				dest[j] = new(interface{})
+			case throughPointer(structType, index):
+				// scanned into pointer, so pointer to struct is allocated only when some of its columns is not NULL
+				dest[j] = reflect.New(reflect.PtrTo(structType.FieldByIndex(index).Type)).Interface()
+				pointed = append(pointed, j)
+			default:
+				dest[j] = elem.Elem().FieldByIndex(index).Addr().Interface()
+			}
+		}
+
+		if err = rows.Scan(dest...); err != nil {
+			return fmt.Errorf("scanning row %d failed: %w", row, err)
+		}
+
+		for _, j := range pointed {
+			if scanned := reflect.ValueOf(dest[j]).Elem(); !scanned.IsNil() {
+				setField(elem.Elem(), fields[columns[j]], scanned.Elem())
+			}
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+
+	return rows.Err()
+}
+
+// throughPointer returns true when field at index is a field of struct pointed to by another field.
+func throughPointer(structType reflect.Type, index []int) bool {
+	for _, j := range index[:len(index)-1] {
+		structType = structType.Field(j).Type
+		if structType.Kind() == reflect.Ptr {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setField sets field at index allocating nil pointers to structs on the way.
+func setField(value reflect.Value, index []int, fieldValue reflect.Value) {
+	for _, j := range index[:len(index)-1] {
+		value = value.Field(j)
+
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+
+			value = value.Elem()
+		}
+	}
+
+	value.Field(index[len(index)-1]).Set(fieldValue)
+}
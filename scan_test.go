@@ -0,0 +1,244 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver returns rows registered in fakeTables. Query is the name of the table.
+type fakeDriver struct{}
+
+type fakeTable struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var fakeTables = map[string]fakeTable{}
+
+func init() {
+	sql.Register("mapify-fake", fakeDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{table: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct{ table string }
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return 0 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("not supported") }
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	table, ok := fakeTables[s.table]
+	if !ok {
+		return nil, errors.New("no such table")
+	}
+
+	return &fakeRows{table: table}, nil
+}
+
+type fakeRows struct {
+	table fakeTable
+	next  int
+}
+
+func (r *fakeRows) Columns() []string { return r.table.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.table.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.table.rows[r.next])
+	r.next++
+
+	return nil
+}
+
+func queryFake(t *testing.T, table fakeTable) *sql.Rows {
+	t.Helper()
+
+	fakeTables[t.Name()] = table
+
+	db, err := sql.Open("mapify-fake", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	rows, err := db.Query(t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = rows.Close()
+	})
+
+	return rows
+}
+
+var createdAt = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var usersTable = fakeTable{
+	columns: []string{"id", "name", "created_at", "address_city", "unknown"},
+	rows: [][]driver.Value{
+		{int64(1), "first", createdAt, "city", "x"},
+		{int64(2), nil, createdAt, nil, "y"},
+	},
+}
+
+func TestScanMaps(t *testing.T) {
+	rows := queryFake(t, usersTable)
+	// when
+	actual, err := mapify.ScanMaps(rows)
+	// then
+	require.NoError(t, err)
+	expected := []map[string]interface{}{
+		{"id": int64(1), "name": "first", "created_at": createdAt, "address_city": "city", "unknown": "x"},
+		{"id": int64(2), "name": nil, "created_at": createdAt, "address_city": nil, "unknown": "y"},
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapper_ScanStructs(t *testing.T) {
+	type scannedAddress struct {
+		City sql.NullString
+	}
+
+	type scannedUser struct {
+		ID        int
+		Name      *string
+		CreatedAt time.Time
+		Address   scannedAddress
+		Ignored   string
+	}
+
+	mapper := mapify.Mapper{
+		Filter: func(path string, e mapify.Element) (bool, error) {
+			return path != ".Ignored", nil
+		},
+		Rename: func(path string, e mapify.Element) (string, error) {
+			return strings.ToLower(mapify.ScreamingSnakeCase(e.Name())), nil
+		},
+	}
+
+	name := "first"
+	expected := []scannedUser{
+		{ID: 1, Name: &name, CreatedAt: createdAt, Address: scannedAddress{City: sql.NullString{String: "city", Valid: true}}},
+		{ID: 2, CreatedAt: createdAt},
+	}
+
+	t.Run("should scan rows into slice of structs", func(t *testing.T) {
+		rows := queryFake(t, usersTable)
+		var actual []scannedUser
+		// when
+		err := mapper.ScanStructs(rows, &actual, mapify.ColumnsOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should scan rows into slice of pointers", func(t *testing.T) {
+		rows := queryFake(t, usersTable)
+		var actual []*scannedUser
+		// when
+		err := mapper.ScanStructs(rows, &actual, mapify.ColumnsOptions{})
+		// then
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.Equal(t, expected[0], *actual[0])
+		assert.Equal(t, expected[1], *actual[1])
+	})
+
+	t.Run("should scan pointer to nested struct", func(t *testing.T) {
+		type scannedOrder struct {
+			ID      int
+			Billing *scannedAddress
+		}
+
+		rows := queryFake(t, fakeTable{
+			columns: []string{"id", "billing_city"},
+			rows: [][]driver.Value{
+				{int64(1), "city"},
+				{int64(2), nil},
+			},
+		})
+		var actual []scannedOrder
+		// when
+		err := mapper.ScanStructs(rows, &actual, mapify.ColumnsOptions{})
+		// then
+		require.NoError(t, err)
+		expected := []scannedOrder{
+			{ID: 1, Billing: &scannedAddress{City: sql.NullString{String: "city", Valid: true}}},
+			{ID: 2},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should not scan nested struct when ShouldConvert returned false", func(t *testing.T) {
+		rows := queryFake(t, fakeTable{
+			columns: []string{"Address"},
+			rows:    [][]driver.Value{{"city"}},
+		})
+		mapper := mapify.Mapper{
+			ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+				return false, nil
+			},
+		}
+		var actual []struct{ Address struct{ City string } }
+		// when
+		err := mapper.ScanStructs(rows, &actual, mapify.ColumnsOptions{})
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when value cannot be scanned", func(t *testing.T) {
+		rows := queryFake(t, fakeTable{
+			columns: []string{"ID"},
+			rows:    [][]driver.Value{{"not a number"}},
+		})
+		var actual []struct{ ID int }
+		// when
+		err := mapify.Mapper{}.ScanStructs(rows, &actual, mapify.ColumnsOptions{})
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when Rename failed", func(t *testing.T) {
+		renameError := errors.New("rename failed")
+		mapper := mapify.Mapper{
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return "", renameError
+			},
+		}
+		var actual []scannedUser
+		// when
+		err := mapper.ScanStructs(&sql.Rows{}, &actual, mapify.ColumnsOptions{})
+		// then
+		assert.ErrorIs(t, err, renameError)
+	})
+
+	t.Run("should return error for invalid target", func(t *testing.T) {
+		targets := []interface{}{nil, []scannedUser{}, &[]int{}, (*[]scannedUser)(nil)}
+
+		for _, target := range targets {
+			err := mapify.Mapper{}.ScanStructs(&sql.Rows{}, target, mapify.ColumnsOptions{})
+			assert.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,458 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// SchemaVersion is the JSON Schema dialect used by Mapper.Schema.
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document. Only keywords used by Mapper.Schema are supported.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// SchemaType is a list of JSON types, such as "object" or "null". Single type is encoded as a JSON string.
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// SchemaOptions configures Mapper.Schema.
+type SchemaOptions struct {
+	// Sample is a value of the described type. When set, callbacks are run with values found in Sample instead of
+	// zero values, MapValue is run and the schema describes the values returned by it. Keys of maps found in
+	// Sample become properties.
+	Sample interface{}
+}
+
+// Schema generates JSON Schema describing the output of MapAny for values of type t. Properties are renamed
+// using Rename, fields rejected by Filter are excluded and named structs converted by ShouldConvert are put
+// into "$defs". The same struct type gets separate definitions when callbacks describe it differently
+// depending on the path.
+//
+// By default, callbacks are run with zero values (new values for pointers) and MapValue is not run, so the
+// schema is built using static rules only. Entries of maps are described by "additionalProperties", for which
+// callbacks are run with "*" as a key, for example ".Labels.*.Name".
+func (i Mapper) Schema(t reflect.Type, options SchemaOptions) (*Schema, error) {
+	value := reflect.New(t).Elem()
+
+	if options.Sample != nil {
+		value = reflect.ValueOf(options.Sample)
+		if value.Type() != t {
+			return nil, fmt.Errorf("sample must be %s, got %s", t, value.Type())
+		}
+	}
+
+	builder := &schemaBuilder{
+		Mapper:    i.newInstance(),
+		sample:    options.Sample != nil,
+		defs:      map[string]*Schema{},
+		defTypes:  map[string]reflect.Type{},
+		building:  map[reflect.Type]string{},
+		recursive: map[string]bool{},
+	}
+
+	schema, err := builder.anySchema("", value)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Schema = SchemaVersion
+
+	if len(builder.defs) > 0 {
+		schema.Defs = builder.defs
+	}
+
+	return schema, nil
+}
+
+type schemaBuilder struct {
+	Mapper
+	sample   bool
+	defs     map[string]*Schema
+	defTypes map[string]reflect.Type
+	// building contains definition names of structs which schemas are being built. Root struct has empty name.
+	building  map[reflect.Type]string
+	recursive map[string]bool
+}
+
+func (b *schemaBuilder) anySchema(path string, value reflect.Value) (*Schema, error) {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Interface || !value.IsValid() {
+		return &Schema{}, nil
+	}
+
+	reflectType := value.Type()
+
+	switch {
+	case reflectType.Kind() == reflect.Ptr && reflectType.Elem().Kind() == reflect.Struct:
+		if value.IsNil() {
+			value = reflect.New(reflectType.Elem())
+		}
+
+		shouldConvert, err := b.ShouldConvert(path, value)
+		if err != nil {
+			return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+		}
+
+		if !shouldConvert {
+			return leafSchema(reflectType), nil
+		}
+
+		schema, err := b.structSchema(path, value.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return nullable(schema), nil
+	case reflectType.Kind() == reflect.Struct ||
+		(reflectType.Kind() == reflect.Map && reflectType.Key().Kind() == reflect.String):
+		shouldConvert, err := b.ShouldConvert(path, value)
+		if err != nil {
+			return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+		}
+
+		if !shouldConvert {
+			return leafSchema(reflectType), nil
+		}
+
+		if reflectType.Kind() == reflect.Struct {
+			return b.structSchema(path, value)
+		}
+
+		return b.stringMapSchema(path, value)
+	case reflectType.Kind() == reflect.Slice:
+		return b.sliceSchema(path, value)
+	default:
+		return leafSchema(reflectType), nil
+	}
+}
+
+// structSchema returns a reference to the definition of named struct and an inline schema for anonymous struct.
+func (b *schemaBuilder) structSchema(path string, value reflect.Value) (*Schema, error) {
+	reflectType := value.Type()
+
+	if path == "" { // root struct is not put into definitions
+		b.building[reflectType] = ""
+		defer delete(b.building, reflectType)
+
+		return b.objectSchema(path, value)
+	}
+
+	if name, ok := b.building[reflectType]; ok {
+		b.recursive[name] = true
+
+		return refSchema(name), nil
+	}
+
+	if reflectType.Name() == "" {
+		return b.objectSchema(path, value)
+	}
+
+	name := reflectType.Name()
+	for j := 2; b.defs[name] != nil || b.defTypes[name] != nil; j++ {
+		name = reflectType.Name() + strconv.Itoa(j)
+	}
+
+	b.defTypes[name] = reflectType
+	b.building[reflectType] = name
+
+	schema, err := b.objectSchema(path, value)
+
+	delete(b.building, reflectType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.recursive[name] {
+		for existingName, existing := range b.defs {
+			if b.defTypes[existingName] == reflectType && reflect.DeepEqual(existing, schema) {
+				delete(b.defTypes, name)
+
+				return refSchema(existingName), nil
+			}
+		}
+	}
+
+	b.defs[name] = schema
+
+	return refSchema(name), nil
+}
+
+func (b *schemaBuilder) objectSchema(path string, value reflect.Value) (*Schema, error) {
+	schema := &Schema{Type: SchemaType{"object"}, Properties: map[string]*Schema{}}
+	reflectType := value.Type()
+
+	for j := 0; j < reflectType.NumField(); j++ {
+		field := reflectType.Field(j)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		element := Element{name: field.Name, Value: value.Field(j), field: &field}
+
+		if err := b.addProperty(schema, fieldPath, element); err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+func (b *schemaBuilder) stringMapSchema(path string, value reflect.Value) (*Schema, error) {
+	schema := &Schema{Type: SchemaType{"object"}}
+	elemType := value.Type().Elem()
+
+	keys := value.MapKeys()
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a].String() < keys[b].String()
+	})
+
+	if len(keys) > 0 {
+		schema.Properties = map[string]*Schema{}
+	}
+
+	for _, key := range keys {
+		element := Element{name: key.String(), Value: value.MapIndex(key)}
+
+		if err := b.addProperty(schema, path+"."+key.String(), element); err != nil {
+			return nil, err
+		}
+	}
+
+	schema.Required = nil // map entries are optional
+
+	additional, err := b.anySchema(path+".*", zeroValue(elemType))
+	if err != nil {
+		return nil, err
+	}
+
+	schema.AdditionalProperties = additional
+
+	return schema, nil
+}
+
+// addProperty runs Filter, Rename and (for samples) MapValue for the element.
+func (b *schemaBuilder) addProperty(schema *Schema, path string, element Element) error {
+	element, err := b.prepare(path, element)
+	if err != nil {
+		return err
+	}
+
+	accepted, err := b.Filter(path, element)
+	if err != nil {
+		return fmt.Errorf("Filter failed: %w", err)
+	}
+
+	if !accepted {
+		return nil
+	}
+
+	renamed, err := b.Rename(path, element)
+	if err != nil {
+		return fmt.Errorf("Rename failed: %w", err)
+	}
+
+	value := element.Value
+	isNull := false
+
+	if b.sample {
+		mapped, err := b.MapValue(path, element)
+		if err != nil {
+			return fmt.Errorf("MapValue failed: %w", err)
+		}
+
+		value = reflect.ValueOf(mapped)
+		if !value.IsValid() {
+			value = zeroValue(element.Type())
+			isNull = true
+		}
+	}
+
+	property, err := b.anySchema(path, value)
+	if err != nil {
+		return err
+	}
+
+	if isNull {
+		property = nullable(property)
+	}
+
+	if _, exists := schema.Properties[renamed]; !exists {
+		schema.Required = append(schema.Required, renamed)
+	}
+
+	schema.Properties[renamed] = property
+
+	return nil
+}
+
+func (b *schemaBuilder) sliceSchema(path string, value reflect.Value) (*Schema, error) {
+	elemType := value.Type().Elem()
+
+	var elementSchema func(path string, elem reflect.Value) (*Schema, error)
+
+	switch {
+	case elemType.Kind() == reflect.Struct:
+		elementSchema = b.structSchema
+	case elemType.Kind() == reflect.Map && elemType.Key().Kind() == reflect.String:
+		elementSchema = b.stringMapSchema
+	case elemType.Kind() == reflect.Slice && (elemType.Elem().Kind() == reflect.Struct ||
+		(elemType.Elem().Kind() == reflect.Map && elemType.Elem().Key().Kind() == reflect.String)):
+		elementSchema = b.sliceSchema
+	default:
+		return leafSchema(value.Type()), nil
+	}
+
+	shouldConvert, err := b.ShouldConvert(path, value)
+	if err != nil {
+		return nil, fmt.Errorf("ShouldConvert failed: %w", err)
+	}
+
+	if !shouldConvert {
+		return leafSchema(value.Type()), nil
+	}
+
+	var items []*Schema
+
+	for j := 0; j < value.Len() || (j == 0 && value.Len() == 0); j++ {
+		elem := zeroValue(elemType)
+		if j < value.Len() {
+			elem = value.Index(j)
+		}
+
+		schema, err := elementSchema(slicePath(path, j), elem)
+		if err != nil {
+			return nil, err
+		}
+
+		items = appendDistinct(items, schema)
+	}
+
+	schema := &Schema{Type: SchemaType{"array"}, Items: items[0]}
+	if len(items) > 1 {
+		schema.Items = &Schema{AnyOf: items}
+	}
+
+	if elemType.Kind() == reflect.Slice {
+		schema = nullable(schema) // MapAny returns nil for empty 2d slice
+	}
+
+	return schema, nil
+}
+
+func appendDistinct(schemas []*Schema, schema *Schema) []*Schema {
+	for _, s := range schemas {
+		if reflect.DeepEqual(s, schema) {
+			return schemas
+		}
+	}
+
+	return append(schemas, schema)
+}
+
+// leafSchema describes values which are not converted by MapAny, as they are encoded by encoding/json.
+func leafSchema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	case t.Implements(jsonMarshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType):
+		return &Schema{Type: SchemaType{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(leafSchema(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaType{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}}
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{"string", "null"}, ContentEncoding: "base64"}
+		}
+
+		return nullable(&Schema{Type: SchemaType{"array"}, Items: leafSchema(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: SchemaType{"array"}, Items: leafSchema(t.Elem())}
+	case reflect.Map:
+		return nullable(&Schema{Type: SchemaType{"object"}, AdditionalProperties: leafSchema(t.Elem())})
+	case reflect.Struct:
+		return &Schema{Type: SchemaType{"object"}}
+	default:
+		return &Schema{}
+	}
+}
+
+func nullable(schema *Schema) *Schema {
+	switch {
+	case len(schema.Type) > 0:
+		for _, t := range schema.Type {
+			if t == "null" {
+				return schema
+			}
+		}
+
+		copied := *schema
+		copied.Type = append(append(SchemaType{}, schema.Type...), "null")
+
+		return &copied
+	case schema.Ref != "":
+		return &Schema{AnyOf: []*Schema{schema, {Type: SchemaType{"null"}}}}
+	default:
+		return schema // any value, including null
+	}
+}
+
+func refSchema(name string) *Schema {
+	if name == "" {
+		return &Schema{Ref: "#"}
+	}
+
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// zeroValue returns zero value of t, or pointer to zero value when t is a pointer to struct.
+func zeroValue(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+		return reflect.New(t.Elem())
+	}
+
+	return reflect.New(t).Elem()
+}
@@ -0,0 +1,193 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaOrder struct {
+	ID        int
+	Secret    string
+	Created   time.Time
+	Shipping  schemaAddress
+	Billing   *schemaAddress
+	Items     []schemaItem
+	Tags      []string
+	Labels    map[string]string
+	Data      []byte
+	Any       interface{}
+	Anonymous struct{ Note string }
+}
+
+type schemaAddress struct {
+	City string
+}
+
+type schemaItem struct {
+	Price float64
+}
+
+type schemaNode struct {
+	Children []schemaNode
+}
+
+func TestMapper_Schema(t *testing.T) {
+	t.Run("should generate schema using static rules", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+				return value.Type() != reflect.TypeOf(time.Time{}), nil
+			},
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return e.Name() != "Secret", nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return strings.ToLower(e.Name()), nil
+			},
+		}
+		// when
+		schema, err := mapper.Schema(reflect.TypeOf(schemaOrder{}), mapify.SchemaOptions{})
+		// then
+		require.NoError(t, err)
+		assertSchema(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"created": {"type": "string", "format": "date-time"},
+				"shipping": {"$ref": "#/$defs/schemaAddress"},
+				"billing": {"anyOf": [{"$ref": "#/$defs/schemaAddress"}, {"type": "null"}]},
+				"items": {"type": "array", "items": {"$ref": "#/$defs/schemaItem"}},
+				"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"data": {"type": ["string", "null"], "contentEncoding": "base64"},
+				"any": {},
+				"anonymous": {"type": "object", "properties": {"note": {"type": "string"}}, "required": ["note"]}
+			},
+			"required": ["id", "created", "shipping", "billing", "items", "tags", "labels", "data", "any", "anonymous"],
+			"$defs": {
+				"schemaAddress": {"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"]},
+				"schemaItem": {"type": "object", "properties": {"price": {"type": "number"}}, "required": ["price"]}
+			}
+		}`, schema)
+	})
+
+	t.Run("should generate separate definitions when rules depend on path", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return path != ".Billing.City", nil
+			},
+			ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+				return strings.HasSuffix(value.Type().String(), "Address") || path == "", nil
+			},
+		}
+		// when
+		schema, err := mapper.Schema(reflect.TypeOf(schemaOrder{}), mapify.SchemaOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "#/$defs/schemaAddress", schema.Properties["Shipping"].Ref)
+		assert.Equal(t, "#/$defs/schemaAddress2", schema.Properties["Billing"].AnyOf[0].Ref)
+		assert.Len(t, schema.Defs["schemaAddress"].Properties, 1)
+		assert.Empty(t, schema.Defs["schemaAddress2"].Properties)
+	})
+
+	t.Run("should reference recursive type", func(t *testing.T) {
+		schema, err := mapify.Mapper{}.Schema(reflect.TypeOf(schemaNode{}), mapify.SchemaOptions{})
+		// then
+		require.NoError(t, err)
+		assertSchema(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {"Children": {"type": "array", "items": {"$ref": "#"}}},
+			"required": ["Children"]
+		}`, schema)
+	})
+
+	t.Run("should evaluate sample value", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			MapValue: func(path string, e mapify.Element) (interface{}, error) {
+				if path == ".id" {
+					return "converted to string", nil
+				}
+
+				return e.Interface(), nil
+			},
+		}
+		sample := map[string]interface{}{
+			"id":    1,
+			"items": []map[string]interface{}{{"price": 1.5}, {"price": "free"}},
+			"null":  nil,
+		}
+		// when
+		schema, err := mapper.Schema(reflect.TypeOf(sample), mapify.SchemaOptions{Sample: sample})
+		// then
+		require.NoError(t, err)
+		assertSchema(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"id": {"type": "string"},
+				"items": {"type": "array", "items": {"anyOf": [
+					{"type": "object", "properties": {"price": {"type": "number"}}, "additionalProperties": {}},
+					{"type": "object", "properties": {"price": {"type": "string"}}, "additionalProperties": {}}
+				]}},
+				"null": {}
+			},
+			"additionalProperties": {}
+		}`, schema)
+	})
+
+	t.Run("should return error when sample has different type", func(t *testing.T) {
+		_, err := mapify.Mapper{}.Schema(reflect.TypeOf(schemaItem{}), mapify.SchemaOptions{Sample: schemaAddress{}})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when callback failed", func(t *testing.T) {
+		callbackError := errors.New("failed")
+		mappers := []mapify.Mapper{
+			{
+				ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+					return false, callbackError
+				},
+			},
+			{
+				Filter: func(path string, e mapify.Element) (bool, error) {
+					return false, callbackError
+				},
+			},
+			{
+				Rename: func(path string, e mapify.Element) (string, error) {
+					return "", callbackError
+				},
+			},
+			{
+				MapValue: func(path string, e mapify.Element) (interface{}, error) {
+					return nil, callbackError
+				},
+			},
+		}
+
+		for _, mapper := range mappers {
+			_, err := mapper.Schema(reflect.TypeOf(schemaItem{}), mapify.SchemaOptions{Sample: schemaItem{}})
+			assert.ErrorIs(t, err, callbackError)
+		}
+	})
+}
+
+func assertSchema(t *testing.T, expected string, schema *mapify.Schema) {
+	t.Helper()
+
+	actual, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
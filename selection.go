@@ -0,0 +1,300 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Selection is a GraphQL-style selection set, such as "{ id total: price items { name } }", which selects and
+// renames fields. Names in Selection are names of map keys after Rename. Optional alias, separated by colon,
+// replaces the name. Nested selection set selects fields of nested structs and maps (for slices - fields of each
+// element). Field without nested selection set is selected with all nested fields. Wildcard "*" selects all
+// fields not listed in the selection set, for example "{ * total: price }" selects all fields and renames price.
+type Selection struct {
+	root *selectionSet
+}
+
+type selectionSet struct {
+	wildcard bool
+	fields   map[string]*selectedField
+}
+
+type selectedField struct {
+	alias string
+	// set is nil when all nested fields are selected
+	set *selectionSet
+}
+
+// ParseSelection parses selection set. Outer braces are optional, commas are ignored the same way as whitespace.
+func ParseSelection(s string) (Selection, error) {
+	p := &selectionParser{tokens: tokenizeSelection(s)}
+
+	braces := p.peek() == "{"
+	if braces {
+		p.next()
+	}
+
+	root, err := p.parseSet(braces)
+	if err != nil {
+		return Selection{}, err
+	}
+
+	if p.peek() != "" {
+		return Selection{}, fmt.Errorf("unexpected %q in selection", p.peek())
+	}
+
+	return Selection{root: root}, nil
+}
+
+type selectionParser struct {
+	tokens []string
+}
+
+func (p *selectionParser) peek() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+
+	return p.tokens[0]
+}
+
+func (p *selectionParser) next() string {
+	token := p.peek()
+	if len(p.tokens) > 0 {
+		p.tokens = p.tokens[1:]
+	}
+
+	return token
+}
+
+// parseSet parses selections until closing brace (when braces is true) or end of input.
+func (p *selectionParser) parseSet(braces bool) (*selectionSet, error) {
+	set := &selectionSet{fields: map[string]*selectedField{}}
+	keys := map[string]string{}
+
+	for {
+		token := p.next()
+
+		switch {
+		case token == "" && braces:
+			return nil, fmt.Errorf("missing } in selection")
+		case token == "" || (token == "}" && braces):
+			if len(set.fields) == 0 && !set.wildcard {
+				return nil, fmt.Errorf("empty selection set")
+			}
+
+			return set, nil
+		case token == "*":
+			set.wildcard = true
+
+			continue
+		case !isSelectionName(token):
+			return nil, fmt.Errorf("unexpected %q in selection", token)
+		}
+
+		name, alias := token, token
+
+		if p.peek() == ":" {
+			p.next()
+
+			name = p.next()
+			if !isSelectionName(name) {
+				return nil, fmt.Errorf("missing field name after alias %s", alias)
+			}
+		}
+
+		field := &selectedField{alias: alias}
+
+		if p.peek() == "{" {
+			p.next()
+
+			nested, err := p.parseSet(true)
+			if err != nil {
+				return nil, err
+			}
+
+			field.set = nested
+		}
+
+		if _, ok := set.fields[name]; ok {
+			return nil, fmt.Errorf("field %s selected more than once", name)
+		}
+
+		if other, ok := keys[alias]; ok {
+			return nil, fmt.Errorf("fields %s and %s have the same name %s", other, name, alias)
+		}
+
+		set.fields[name] = field
+		keys[alias] = name
+	}
+}
+
+func isSelectionName(token string) bool {
+	return token != "" && !strings.ContainsAny(token, "{}:*")
+}
+
+func tokenizeSelection(s string) []string {
+	var tokens []string
+
+	name := strings.Builder{}
+
+	flush := func() {
+		if name.Len() > 0 {
+			tokens = append(tokens, name.String())
+			name.Reset()
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r) || r == ',':
+			flush()
+		case r == '{' || r == '}' || r == ':' || r == '*':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			name.WriteRune(r)
+		}
+	}
+
+	flush()
+
+	return tokens
+}
+
+// WithSelection returns a copy of Mapper which maps only fields selected by s and renames fields with aliases.
+// Fields are selected when both s and Mapper.Filter accept them. Names in s are compared with names returned by
+// Mapper.Rename. Returned Mapper can be used concurrently when the original one can.
+func (i Mapper) WithSelection(s Selection) Mapper {
+	if s.root == nil {
+		return i
+	}
+
+	instantiate := i.instantiate
+	i.instantiate = func(instance Mapper) Mapper {
+		if instantiate != nil {
+			instance = instantiate(instance)
+		}
+
+		return s.instantiate(instance)
+	}
+
+	return i
+}
+
+// instantiate returns instance with Filter and Rename selecting fields. Selected fields are tracked by paths
+// within a single call.
+func (s Selection) instantiate(instance Mapper) Mapper {
+	filter, rename := instance.Filter, instance.Rename
+
+	var selected sync.Map // path -> *selectedField
+
+	instance.Filter = func(path string, e Element) (bool, error) {
+		set := s.root
+
+		if parentPath := parentElementPath(path); parentPath != "" {
+			parent, ok := selected.Load(parentPath)
+			if !ok {
+				return filter(path, e) // parent was selected by wildcard
+			}
+
+			if set = parent.(*selectedField).set; set == nil {
+				return filter(path, e)
+			}
+		}
+
+		name, err := rename(path, e)
+		if err != nil {
+			return false, err
+		}
+
+		field, ok := set.fields[name]
+		if !ok && !set.wildcard {
+			return false, nil
+		}
+
+		if ok {
+			selected.Store(path, field)
+		}
+
+		return filter(path, e)
+	}
+
+	instance.Rename = func(path string, e Element) (string, error) {
+		if field, ok := selected.Load(path); ok {
+			return field.(*selectedField).alias, nil
+		}
+
+		return rename(path, e)
+	}
+
+	return instance
+}
+
+// Validate checks that all fields in the selection exist in type t, which fields are renamed using rename.
+// Fields of maps and interfaces are not checked. Error contains all unknown fields.
+func (s Selection) Validate(t reflect.Type, rename Rename) error {
+	if s.root == nil {
+		return nil
+	}
+
+	if rename == nil {
+		rename = noRename
+	}
+
+	var unknown []string
+
+	if err := s.root.validate(t, "", "", rename, &unknown); err != nil {
+		return err
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return fmt.Errorf("unknown fields in selection: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+func (set *selectionSet) validate(t reflect.Type, path, selectionPath string, rename Rename,
+	unknown *[]string) error {
+
+	t, path = elementType(t, path)
+
+	if t.Kind() == reflect.Interface || t.Kind() == reflect.Map {
+		return nil
+	}
+
+	fields, err := renamedFields(t, path, rename)
+	if err != nil {
+		return err
+	}
+
+	for name, selected := range set.fields {
+		childPath := strings.TrimPrefix(selectionPath+"."+name, ".")
+
+		field, ok := fields[name]
+		if !ok {
+			*unknown = append(*unknown, childPath)
+			continue
+		}
+
+		if selected.set == nil {
+			continue
+		}
+
+		if err = selected.set.validate(field.Type, path+"."+field.Name, childPath, rename, unknown); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
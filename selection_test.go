@@ -0,0 +1,160 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapper_WithSelection(t *testing.T) {
+	v := maskedOrder{
+		ID:       1,
+		Name:     "order",
+		Customer: maskedCustomer{Name: "John", Email: "john@example.com"},
+		Items:    []maskedItem{{Price: 1, Qty: 2}, {Price: 3, Qty: 4}},
+		Grid:     [][]maskedItem{{{Price: 5, Qty: 6}}},
+		Labels:   map[string]string{"a": "1", "b": "2"},
+	}
+	mapper := mapify.Mapper{Rename: lowerRename}
+
+	t.Run("should select and rename fields", func(t *testing.T) {
+		selection, err := mapify.ParseSelection(`{
+			id
+			buyer: customer { name }
+			items { total: price }
+			grid { qty }
+			labels { label: b }
+		}`)
+		require.NoError(t, err)
+		// when
+		actual, err := mapper.WithSelection(selection).MapAny(v)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"id":     1,
+			"buyer":  map[string]interface{}{"name": "John"},
+			"items":  []map[string]interface{}{{"total": 1}, {"total": 3}},
+			"grid":   [][]map[string]interface{}{{{"qty": 6}}},
+			"labels": map[string]interface{}{"label": "2"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should select all fields with wildcard", func(t *testing.T) {
+		selection, err := mapify.ParseSelection("*, number: id, items { * cost: price }")
+		require.NoError(t, err)
+		// when
+		actual, err := mapper.WithSelection(selection).MapAny(v)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"number":   1,
+			"name":     "order",
+			"customer": map[string]interface{}{"name": "John", "email": "john@example.com"},
+			"items":    []map[string]interface{}{{"cost": 1, "qty": 2}, {"cost": 3, "qty": 4}},
+			"grid":     [][]map[string]interface{}{{{"price": 5, "qty": 6}}},
+			"labels":   map[string]interface{}{"a": "1", "b": "2"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should also apply Filter of Mapper", func(t *testing.T) {
+		selection, err := mapify.ParseSelection("ID Name")
+		require.NoError(t, err)
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return path != ".Name", nil
+			},
+		}
+		// when
+		actual, err := mapper.WithSelection(selection).MapAny(v)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"ID": 1}, actual)
+	})
+
+	t.Run("should reuse Mapper for different types", func(t *testing.T) {
+		selection, err := mapify.ParseSelection("* customer { name }")
+		require.NoError(t, err)
+		mapper := mapify.Mapper{Rename: jsonRename}.WithSelection(selection)
+		_, err = mapper.MapAny(v)
+		require.NoError(t, err)
+		client := clientOrder{
+			Customer: maskedCustomer{Name: "John", Email: "john@example.com"},
+			Other:    maskedCustomer{Name: "Jane", Email: "jane@example.com"},
+		}
+		// when
+		actual, err := mapper.MapAny(client)
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"client":   map[string]interface{}{"name": "John", "email": "john@example.com"},
+			"customer": map[string]interface{}{"name": "Jane"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return error when rename failed", func(t *testing.T) {
+		renameError := errors.New("rename failed")
+		selection, err := mapify.ParseSelection("id")
+		require.NoError(t, err)
+		mapper := mapify.Mapper{
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return "", renameError
+			},
+		}
+		// when
+		_, err = mapper.WithSelection(selection).MapAny(v)
+		// then
+		assert.ErrorIs(t, err, renameError)
+	})
+}
+
+func TestParseSelection(t *testing.T) {
+	t.Run("should return error for invalid selection", func(t *testing.T) {
+		invalid := []string{
+			"",
+			"{}",
+			"{ id",
+			"id }",
+			"items { }",
+			"total:",
+			"total: { price }",
+			"id id",
+			"a: id a: name",
+			"{ id } name",
+			"::",
+		}
+
+		for _, s := range invalid {
+			_, err := mapify.ParseSelection(s)
+			assert.Error(t, err, s)
+		}
+	})
+}
+
+func TestSelection_Validate(t *testing.T) {
+	orderType := reflect.TypeOf(maskedOrder{})
+
+	t.Run("should accept existing fields", func(t *testing.T) {
+		selection, err := mapify.ParseSelection("{ * buyer: customer { email } items { price } labels { any } }")
+		require.NoError(t, err)
+		assert.NoError(t, selection.Validate(orderType, lowerRename))
+	})
+
+	t.Run("should report unknown fields", func(t *testing.T) {
+		selection, err := mapify.ParseSelection("{ idd customer { phone } items { total: prize } }")
+		require.NoError(t, err)
+		// when
+		err = selection.Validate(orderType, lowerRename)
+		// then
+		assert.EqualError(t, err, "unknown fields in selection: customer.phone, idd, items.prize")
+	})
+}
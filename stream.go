@@ -0,0 +1,62 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+//go:build go1.18
+
+package mapify
+
+import (
+	"context"
+	"fmt"
+)
+
+// MapChan maps each value received from in and sends results to the returned unbuffered channel, so the reading
+// from in is paused until previous result is received. Values are mapped with paths of slice elements:
+// "[0]", "[1]" and so on.
+//
+// Returned channels are closed when in is closed, ctx is done or mapping failed. In two latter cases the error
+// is sent to the error channel first.
+func MapChan[T any](ctx context.Context, m Mapper, in <-chan T) (<-chan interface{}, <-chan error) {
+	out := make(chan interface{})
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(out)
+
+		instance := m.newInstance()
+
+		for j := 0; ; j++ {
+			var v T
+
+			select {
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			case received, ok := <-in:
+				if !ok {
+					return
+				}
+
+				v = received
+			}
+
+			path := slicePath("", j)
+
+			mapped, err := instance.mapAny(path, v)
+			if err != nil {
+				errs <- fmt.Errorf("%s: %w", path, err)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			case out <- mapped:
+			}
+		}
+	}()
+
+	return out, errs
+}
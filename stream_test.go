@@ -0,0 +1,79 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+//go:build go1.18
+
+package mapify_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapChan(t *testing.T) {
+	t.Run("should map all values", func(t *testing.T) {
+		in := make(chan row, 2)
+		in <- row{ID: 1}
+		in <- row{ID: 2}
+		close(in)
+		// when
+		out, errs := mapify.MapChan(context.Background(), mapify.Mapper{}, in)
+		// then
+		var actual []interface{}
+		for mapped := range out {
+			actual = append(actual, mapped)
+		}
+
+		expected := []interface{}{
+			map[string]interface{}{"ID": 1},
+			map[string]interface{}{"ID": 2},
+		}
+		assert.Equal(t, expected, actual)
+		assert.NoError(t, <-errs)
+	})
+
+	t.Run("should send mapping error", func(t *testing.T) {
+		givenError := stringError("err")
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				if path == "[1].ID" {
+					return false, givenError
+				}
+
+				return true, nil
+			},
+		}
+		in := make(chan row, 3)
+		in <- row{ID: 1}
+		in <- row{ID: 2}
+		in <- row{ID: 3}
+		close(in)
+		// when
+		out, errs := mapify.MapChan(context.Background(), mapper, in)
+		// then
+		var actual []interface{}
+		for mapped := range out {
+			actual = append(actual, mapped)
+		}
+
+		assert.Equal(t, []interface{}{map[string]interface{}{"ID": 1}}, actual)
+		err := <-errs
+		assert.ErrorIs(t, err, givenError)
+		assert.Contains(t, err.Error(), "[1]")
+	})
+
+	t.Run("should stop when context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		in := make(chan row)
+		out, errs := mapify.MapChan(ctx, mapify.Mapper{}, in)
+		// when
+		cancel()
+		// then
+		_, ok := <-out
+		assert.False(t, ok)
+		assert.ErrorIs(t, <-errs, context.Canceled)
+	})
+}
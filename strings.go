@@ -0,0 +1,144 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// NestedStyle specifies how MapStrings converts nested maps and slices.
+type NestedStyle int
+
+const (
+	// NestedFlattened flattens nested values using Flatten, for example {"labels.app": "web"}.
+	NestedFlattened NestedStyle = iota
+	// NestedJSON encodes nested values as JSON, for example {"labels": `{"app":"web"}`}.
+	NestedJSON
+)
+
+// StringsOptions configures MapStrings.
+type StringsOptions struct {
+	Nested NestedStyle
+	// Flatten configures flattening when Nested is NestedFlattened.
+	Flatten FlattenOptions
+	// Format formats leaf values, including nil. Default is FormatString.
+	Format Formatter
+}
+
+// MapStrings maps v using MapAny and converts the result into map[string]string, which can be used for headers,
+// labels or annotations. v must be converted to a map, so it has to be a struct or a map with string key.
+//
+// Every leaf value is formatted with options.Format. Nested maps and slices are flattened or encoded as JSON,
+// depending on options.Nested. Empty nested maps and slices, which cannot be flattened, are encoded as JSON too.
+// Nil maps and slices are formatted as nil.
+//
+// Values implementing encoding.TextMarshaler (such as time.Time) are never converted, so they are formatted
+// as leaf values. For other values ShouldConvert is run as usual.
+func (i Mapper) MapStrings(v interface{}, options StringsOptions) (map[string]string, error) {
+	shouldConvert := i.ShouldConvert
+	i.ShouldConvert = func(path string, value reflect.Value) (bool, error) {
+		if value.Type().Implements(textMarshalerType) {
+			return false, nil
+		}
+
+		if shouldConvert == nil {
+			return true, nil
+		}
+
+		return shouldConvert(path, value)
+	}
+
+	mapped, err := i.MapAny(v)
+	if err != nil {
+		return nil, err
+	}
+
+	value := reflect.ValueOf(mapped)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("%T was not converted to a map", v)
+	}
+
+	format := options.Format
+	if format == nil {
+		format = FormatString
+	}
+
+	entries := map[string]interface{}{}
+
+	if options.Nested == NestedFlattened {
+		entries = Flatten(mapped, options.Flatten)
+	} else {
+		iter := value.MapRange()
+		for iter.Next() {
+			entries[iter.Key().String()] = iter.Value().Interface()
+		}
+	}
+
+	result := make(map[string]string, len(entries))
+
+	for key, entry := range entries {
+		formatted, err := formatString(entry, format)
+		if err != nil {
+			return nil, fmt.Errorf("formatting %s failed: %w", key, err)
+		}
+
+		result[key] = formatted
+	}
+
+	return result, nil
+}
+
+func formatString(v interface{}, format Formatter) (string, error) {
+	value := dereferenceInterface(reflect.ValueOf(v))
+
+	if !isContainer(value) {
+		return format(v)
+	}
+
+	if value.Kind() != reflect.Array && value.IsNil() {
+		return format(nil)
+	}
+
+	encoded, err := json.Marshal(v)
+
+	return string(encoded), err
+}
+
+// FormatString is the default Formatter of MapStrings. It formats nil as empty string and floats without
+// exponent, for example 1e6 as "1000000". Other values are formatted using FormatValue.
+func FormatString(value interface{}) (string, error) {
+	reflectValue := reflect.ValueOf(value)
+
+	for reflectValue.Kind() == reflect.Ptr && !reflectValue.IsNil() {
+		reflectValue = reflectValue.Elem()
+	}
+
+	switch {
+	case !reflectValue.IsValid() || (reflectValue.Kind() == reflect.Ptr && reflectValue.IsNil()):
+		return "", nil
+	case reflectValue.Kind() == reflect.Float32 || reflectValue.Kind() == reflect.Float64:
+		if hasFormattingMethod(value) {
+			break
+		}
+
+		return strconv.FormatFloat(reflectValue.Float(), 'f', -1, reflectValue.Type().Bits()), nil
+	}
+
+	return FormatValue(value)
+}
+
+func hasFormattingMethod(value interface{}) bool {
+	switch value.(type) {
+	case fmt.Stringer, encoding.TextMarshaler:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,162 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deployment struct {
+	Name     string
+	Replicas int
+	Ratio    float64
+	Enabled  bool
+	Created  time.Time
+	Owner    *string
+	Labels   map[string]string
+	Ports    []int
+	Empty    []string
+}
+
+func TestMapper_MapStrings(t *testing.T) {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := deployment{
+		Name:     "web",
+		Replicas: 3,
+		Ratio:    1e6,
+		Enabled:  true,
+		Created:  created,
+		Labels:   map[string]string{"app": "web"},
+		Ports:    []int{80, 443},
+	}
+
+	t.Run("should flatten nested values", func(t *testing.T) {
+		actual, err := mapify.Mapper{}.MapStrings(d, mapify.StringsOptions{})
+		// then
+		require.NoError(t, err)
+		expected := map[string]string{
+			"Name":       "web",
+			"Replicas":   "3",
+			"Ratio":      "1000000",
+			"Enabled":    "true",
+			"Created":    "2022-01-02T03:04:05Z",
+			"Owner":      "",
+			"Labels.app": "web",
+			"Ports.0":    "80",
+			"Ports.1":    "443",
+			"Empty":      "",
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should use flatten options", func(t *testing.T) {
+		options := mapify.StringsOptions{
+			Flatten: mapify.FlattenOptions{Separator: "/", IndexStyle: mapify.IndexInBrackets},
+		}
+		// when
+		actual, err := mapify.Mapper{}.MapStrings(d, options)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "web", actual["Labels/app"])
+		assert.Equal(t, "443", actual["Ports[1]"])
+	})
+
+	t.Run("should encode nested values as JSON", func(t *testing.T) {
+		actual, err := mapify.Mapper{}.MapStrings(d, mapify.StringsOptions{Nested: mapify.NestedJSON})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `{"app":"web"}`, actual["Labels"])
+		assert.Equal(t, `[80,443]`, actual["Ports"])
+		assert.Equal(t, "", actual["Empty"])
+		assert.Equal(t, "3", actual["Replicas"])
+		assert.Len(t, actual, 9)
+	})
+
+	t.Run("should encode empty nested values as JSON", func(t *testing.T) {
+		v := map[string]interface{}{"map": map[string]int{}, "slice": []int{}}
+		// when
+		actual, err := mapify.Mapper{}.MapStrings(v, mapify.StringsOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"map": "{}", "slice": "[]"}, actual)
+	})
+
+	t.Run("should use custom formatter", func(t *testing.T) {
+		options := mapify.StringsOptions{
+			Format: func(value interface{}) (string, error) {
+				if value == nil {
+					return "null", nil
+				}
+
+				return mapify.FormatString(value)
+			},
+		}
+		// when
+		actual, err := mapify.Mapper{}.MapStrings(map[string]interface{}{"a": nil, "b": 1}, options)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "null", "b": "1"}, actual)
+	})
+
+	t.Run("should return error when formatter failed", func(t *testing.T) {
+		formatError := errors.New("format failed")
+		options := mapify.StringsOptions{
+			Format: func(value interface{}) (string, error) {
+				return "", formatError
+			},
+		}
+		// when
+		_, err := mapify.Mapper{}.MapStrings(map[string]int{"a": 1}, options)
+		// then
+		assert.ErrorIs(t, err, formatError)
+	})
+
+	t.Run("should return error when value was not converted to map", func(t *testing.T) {
+		_, err := mapify.Mapper{}.MapStrings([]int{1}, mapify.StringsOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatString(t *testing.T) {
+	name := "name"
+	tests := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"nil":            {value: nil, expected: ""},
+		"nil pointer":    {value: (*int)(nil), expected: ""},
+		"pointer":        {value: &name, expected: "name"},
+		"int":            {value: -12, expected: "-12"},
+		"uint8":          {value: uint8(200), expected: "200"},
+		"big float":      {value: 1e21, expected: "1000000000000000000000"},
+		"small float":    {value: 0.25, expected: "0.25"},
+		"float32":        {value: float32(0.1), expected: "0.1"},
+		"bool":           {value: false, expected: "false"},
+		"duration":       {value: time.Minute, expected: "1m0s"},
+		"time":           {value: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), expected: "2022-01-02T00:00:00Z"},
+		"bytes":          {value: []byte("abc"), expected: "abc"},
+		"float stringer": {value: celsius(1.5), expected: "1.5°C"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := mapify.FormatString(test.value)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+type celsius float64
+
+func (c celsius) String() string {
+	return strconv.FormatFloat(float64(c), 'f', -1, 64) + "°C"
+}
@@ -0,0 +1,266 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HeaderOrder specifies order of columns in a table.
+type HeaderOrder int
+
+const (
+	// HeaderDeclarationOrder orders columns by declaration of struct fields. Keys of maps are sorted.
+	// Columns found only in later rows are put after columns found in earlier rows.
+	HeaderDeclarationOrder HeaderOrder = iota
+	// HeaderSorted sorts all columns alphabetically.
+	HeaderSorted
+)
+
+// TableOptions configures MapTable and WriteCSV.
+type TableOptions struct {
+	Header HeaderOrder
+	// Separator is put between keys of nested values in column names. Default is ".".
+	Separator string
+	// Missing is the cell value used when row does not have the column or the value is nil.
+	Missing string
+	// SliceSeparator joins elements of slices of scalar values in a single cell, for example "a;b". By default,
+	// each element is put in its own column, such as "tags.0" and "tags.1".
+	SliceSeparator string
+	// Format formats scalar values. Default is FormatValue.
+	Format Formatter
+}
+
+// MapTable maps a slice of structs (or maps) using MapAny and converts each element into a table row. Nested
+// maps and slices are flattened into column names, such as "address.city" or "items.0.price".
+func (i Mapper) MapTable(v interface{}, options TableOptions) (header []string, rows [][]string, err error) {
+	if options.Separator == "" {
+		options.Separator = "."
+	}
+
+	if options.Format == nil {
+		options.Format = FormatValue
+	}
+
+	order := newFieldsOrder()
+	i.elementAdded = order.add
+
+	mapped, err := i.MapAny(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slice := reflect.ValueOf(mapped)
+	if slice.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("%T was not converted to a slice", v)
+	}
+
+	builder := tableBuilder{options: options, order: order, columns: map[string]int{}}
+
+	for j := 0; j < slice.Len(); j++ {
+		row := dereferenceInterface(slice.Index(j))
+		if row.Kind() != reflect.Map || row.Type().Key().Kind() != reflect.String {
+			return nil, nil, fmt.Errorf("element %d was not converted to a map", j)
+		}
+
+		cells := map[string]string{}
+		if err = builder.addCells(cells, "", slicePath("", j), row); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", slicePath("", j), err)
+		}
+
+		builder.rows = append(builder.rows, cells)
+	}
+
+	header, rows = builder.table()
+
+	return header, rows, nil
+}
+
+// WriteCSV writes header and rows returned by MapTable to w and flushes it.
+func (i Mapper) WriteCSV(w *csv.Writer, v interface{}, options TableOptions) error {
+	header, rows, err := i.MapTable(v, options)
+	if err != nil {
+		return err
+	}
+
+	if err = w.Write(header); err != nil {
+		return err
+	}
+
+	return w.WriteAll(rows)
+}
+
+// fieldsOrder records keys of elements added to result maps during a single MapAny call. Result maps are
+// identified by paths of mapped values, which are unique within the call.
+type fieldsOrder struct {
+	mutex sync.Mutex
+	// fields are keys of struct fields in declaration order by path of the struct
+	fields map[string][]string
+	// paths are paths of elements by path of the result map and key
+	paths map[orderedKey]string
+}
+
+type orderedKey struct {
+	path, key string
+}
+
+func newFieldsOrder() *fieldsOrder {
+	return &fieldsOrder{fields: map[string][]string{}, paths: map[orderedKey]string{}}
+}
+
+func (o *fieldsOrder) add(elementPath string, e Element, key string) {
+	path := strings.TrimSuffix(elementPath, "."+e.Name())
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, ok := e.StructField(); ok {
+		o.fields[path] = append(o.fields[path], key)
+	}
+
+	o.paths[orderedKey{path: path, key: key}] = elementPath // the last element added with key is in the map
+}
+
+// path returns path of the value of key in map found at path.
+func (o *fieldsOrder) path(path, key string) string {
+	if elementPath, ok := o.paths[orderedKey{path: path, key: key}]; ok {
+		return elementPath
+	}
+
+	return path + "." + key
+}
+
+// sortedKeys returns keys of map m found at path: keys of struct fields in declaration order followed by
+// remaining keys sorted alphabetically.
+func (o *fieldsOrder) sortedKeys(path string, m reflect.Value) []string {
+	var ordered []string
+
+	seen := map[string]bool{}
+
+	for _, key := range o.fields[path] {
+		if !seen[key] && m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key())).IsValid() {
+			seen[key] = true
+			ordered = append(ordered, key)
+		}
+	}
+
+	var rest []string
+
+	for _, key := range m.MapKeys() {
+		if !seen[key.String()] {
+			rest = append(rest, key.String())
+		}
+	}
+
+	sort.Strings(rest)
+
+	return append(ordered, rest...)
+}
+
+type tableBuilder struct {
+	options TableOptions
+	order   *fieldsOrder
+	columns map[string]int // column name -> index of first appearance
+	header  []string
+	rows    []map[string]string
+}
+
+// addCells adds cells of value found at path.
+func (b *tableBuilder) addCells(cells map[string]string, column, path string, value reflect.Value) error {
+	value = dereferenceInterface(value)
+
+	switch {
+	case !isContainer(value):
+		formatted, ok, err := formatScalar(value, b.options.Format)
+		if err != nil {
+			return fmt.Errorf("formatting %s failed: %w", column, err)
+		}
+
+		if ok {
+			b.addCell(cells, column, formatted)
+		}
+	case value.Kind() == reflect.Map:
+		for _, key := range b.order.sortedKeys(path, value) {
+			mapValue := value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+			if err := b.addCells(cells, b.join(column, key), b.order.path(path, key), mapValue); err != nil {
+				return err
+			}
+		}
+	case b.options.SliceSeparator != "" && !containsContainers(value):
+		var elements []string
+
+		for j := 0; j < value.Len(); j++ {
+			formatted, ok, err := formatScalar(value.Index(j), b.options.Format)
+			if err != nil {
+				return fmt.Errorf("formatting %s failed: %w", b.join(column, strconv.Itoa(j)), err)
+			}
+
+			if !ok {
+				formatted = b.options.Missing
+			}
+
+			elements = append(elements, formatted)
+		}
+
+		b.addCell(cells, column, strings.Join(elements, b.options.SliceSeparator))
+	default:
+		for j := 0; j < value.Len(); j++ {
+			err := b.addCells(cells, b.join(column, strconv.Itoa(j)), slicePath(path, j), value.Index(j))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *tableBuilder) join(column, key string) string {
+	if column == "" {
+		return key
+	}
+
+	return column + b.options.Separator + key
+}
+
+func (b *tableBuilder) addCell(cells map[string]string, column, value string) {
+	if _, ok := b.columns[column]; !ok {
+		b.columns[column] = len(b.header)
+		b.header = append(b.header, column)
+	}
+
+	cells[column] = value
+}
+
+func (b *tableBuilder) table() (header []string, rows [][]string) {
+	header = b.header
+	if b.options.Header == HeaderSorted {
+		sort.Strings(header)
+	}
+
+	rows = make([][]string, len(b.rows))
+
+	for j, cells := range b.rows {
+		row := make([]string, len(header))
+
+		for k, column := range header {
+			cell, ok := cells[column]
+			if !ok {
+				cell = b.options.Missing
+			}
+
+			row[k] = cell
+		}
+
+		rows[j] = row
+	}
+
+	return header, rows
+}
@@ -0,0 +1,129 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reportRow struct {
+	Name    string
+	Address struct {
+		Street string
+		City   string
+	}
+	Tags   []string
+	Extra  map[string]int
+	Amount *int
+}
+
+func TestMapper_MapTable(t *testing.T) {
+	amount := 5
+	first := reportRow{Name: "first", Tags: []string{"a", "b"}, Amount: &amount}
+	first.Address.Street = "s"
+	first.Address.City = "c"
+	second := reportRow{Name: "second", Extra: map[string]int{"z": 1, "y": 2}}
+
+	t.Run("should use declaration order", func(t *testing.T) {
+		header, rows, err := mapify.Mapper{}.MapTable([]reportRow{first, second}, mapify.TableOptions{Missing: "-"})
+		require.NoError(t, err)
+		expectedHeader := []string{
+			"Name", "Address.Street", "Address.City", "Tags.0", "Tags.1", "Amount", "Extra.y", "Extra.z",
+		}
+		assert.Equal(t, expectedHeader, header)
+		expectedRows := [][]string{
+			{"first", "s", "c", "a", "b", "5", "-", "-"},
+			{"second", "", "", "-", "-", "-", "2", "1"},
+		}
+		assert.Equal(t, expectedRows, rows)
+	})
+
+	t.Run("should sort columns and join slices", func(t *testing.T) {
+		options := mapify.TableOptions{
+			Header:         mapify.HeaderSorted,
+			Separator:      "_",
+			SliceSeparator: ";",
+		}
+		// when
+		header, rows, err := mapify.Mapper{}.MapTable([]reportRow{first}, options)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Address_City", "Address_Street", "Amount", "Name", "Tags"}, header)
+		assert.Equal(t, [][]string{{"c", "s", "5", "first", "a;b"}}, rows)
+	})
+
+	t.Run("should map slice of maps", func(t *testing.T) {
+		v := []map[string]interface{}{
+			{"b": 1, "a": []map[string]int{{"x": 1}}},
+		}
+		// when
+		header, rows, err := mapify.Mapper{}.MapTable(v, mapify.TableOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.0.x", "b"}, header)
+		assert.Equal(t, [][]string{{"1", "1"}}, rows)
+	})
+
+	t.Run("should use Rename", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return path == "[0].Name", nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return "name", nil
+			},
+		}
+		// when
+		header, rows, err := mapper.MapTable([]reportRow{first}, mapify.TableOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name"}, header)
+		assert.Equal(t, [][]string{{"first"}}, rows)
+	})
+
+	t.Run("should use declaration order of renamed structs nested in maps", func(t *testing.T) {
+		type place struct {
+			Zip  string
+			City string
+		}
+		type row struct {
+			Places map[string]place
+		}
+		mapper := mapify.Mapper{
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return strings.ToLower(e.Name()), nil
+			},
+		}
+		v := []row{{Places: map[string]place{"Work": {Zip: "1", City: "a"}, "Home": {Zip: "2", City: "b"}}}}
+		// when
+		header, rows, err := mapper.MapTable(v, mapify.TableOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"places.home.zip", "places.home.city", "places.work.zip", "places.work.city"}, header)
+		assert.Equal(t, [][]string{{"2", "b", "1", "a"}}, rows)
+	})
+
+	t.Run("should return error when value is not a slice of structs", func(t *testing.T) {
+		for _, v := range []interface{}{first, []int{1}} {
+			_, _, err := mapify.Mapper{}.MapTable(v, mapify.TableOptions{})
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestMapper_WriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	// when
+	err := mapify.Mapper{}.WriteCSV(csv.NewWriter(&out), []reportRow{{Name: "a,b"}}, mapify.TableOptions{})
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "Name,Address.Street,Address.City\n\"a,b\",,\n", out.String())
+}
@@ -0,0 +1,207 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrKeyConflict is returned by Unflatten when a key is used both as a value and as a parent of another key,
+// for example "db.pool" and "db.pool.size".
+var ErrKeyConflict = errors.New("key conflict")
+
+// Unflatten is the inverse of Flatten. It rebuilds nested map[string]interface{} and []interface{} from keys
+// delimited by options.Separator, for example {"db.pool.size": 10} is converted into
+// {"db": {"pool": {"size": 10}}}.
+//
+// With IndexAsKey style, a map is converted into []interface{} when its keys are exactly 0, 1, ..., n-1. With
+// IndexInBrackets style, only keys in brackets are indexes and they must form such sequence as well.
+// options.MaxDepth is ignored.
+func Unflatten(flat map[string]interface{}, options FlattenOptions) (map[string]interface{}, error) {
+	if options.Separator == "" {
+		options.Separator = "."
+	}
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys) // deterministic error reporting
+
+	root := &unflattenNode{children: map[string]*unflattenNode{}}
+
+	for _, key := range keys {
+		segments, err := parseFlatKey(key, options)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = root.insert(key, segments, flat[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return root.buildMap(options)
+}
+
+type flatKeySegment struct {
+	name  string
+	index bool
+}
+
+func parseFlatKey(key string, options FlattenOptions) ([]flatKeySegment, error) {
+	parts := strings.Split(key, options.Separator)
+
+	if options.IndexStyle != IndexInBrackets {
+		segments := make([]flatKeySegment, len(parts))
+		for j, part := range parts {
+			segments[j] = flatKeySegment{name: part}
+		}
+
+		return segments, nil
+	}
+
+	var segments []flatKeySegment
+
+	for _, part := range parts {
+		bracket := strings.IndexByte(part, '[')
+		if bracket < 0 {
+			segments = append(segments, flatKeySegment{name: part})
+			continue
+		}
+
+		if bracket == 0 {
+			return nil, fmt.Errorf("key %q: index without a name", key)
+		}
+
+		segments = append(segments, flatKeySegment{name: part[:bracket]})
+
+		for rest := part[bracket:]; rest != ""; {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("key %q: malformed index", key)
+			}
+
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("key %q: invalid index %q", key, rest[1:end])
+			}
+
+			segments = append(segments, flatKeySegment{name: strconv.Itoa(index), index: true})
+			rest = rest[end+1:]
+		}
+	}
+
+	return segments, nil
+}
+
+type unflattenNode struct {
+	origin   string // flat key which created the node
+	value    interface{}
+	children map[string]*unflattenNode // nil for values
+	indexes  bool                      // children are accessed by index in brackets
+	names    bool                      // children are accessed by name
+}
+
+func (n *unflattenNode) insert(key string, segments []flatKeySegment, value interface{}) error {
+	for j, segment := range segments {
+		if n.children == nil {
+			return fmt.Errorf("%w: %q is both a value and a parent of %q", ErrKeyConflict, n.origin, key)
+		}
+
+		if segment.index {
+			n.indexes = true
+		} else {
+			n.names = true
+		}
+
+		if n.indexes && n.names {
+			return fmt.Errorf("%w: %q mixes index and name with %q", ErrKeyConflict, key, n.origin)
+		}
+
+		last := j == len(segments)-1
+		child, ok := n.children[segment.name]
+
+		switch {
+		case !ok:
+			child = &unflattenNode{origin: key}
+			if !last {
+				child.children = map[string]*unflattenNode{}
+			}
+
+			n.children[segment.name] = child
+		case last && child.children != nil:
+			return fmt.Errorf("%w: %q is both a value and a parent of %q", ErrKeyConflict, key, child.origin)
+		case last:
+			return fmt.Errorf("%w: %q and %q refer to the same value", ErrKeyConflict, child.origin, key)
+		}
+
+		n = child
+	}
+
+	n.value = value
+
+	return nil
+}
+
+func (n *unflattenNode) build(options FlattenOptions) (interface{}, error) {
+	if n.children == nil {
+		return n.value, nil
+	}
+
+	if n.indexes || (options.IndexStyle == IndexAsKey && isSequence(n.children)) {
+		if !isSequence(n.children) {
+			return nil, fmt.Errorf("indexes of %q do not form a sequence starting from 0", n.origin)
+		}
+
+		slice := make([]interface{}, len(n.children))
+
+		for name, child := range n.children {
+			index, _ := strconv.Atoi(name)
+
+			value, err := child.build(options)
+			if err != nil {
+				return nil, err
+			}
+
+			slice[index] = value
+		}
+
+		return slice, nil
+	}
+
+	return n.buildMap(options)
+}
+
+func (n *unflattenNode) buildMap(options FlattenOptions) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(n.children))
+
+	for name, child := range n.children {
+		value, err := child.build(options)
+		if err != nil {
+			return nil, err
+		}
+
+		result[name] = value
+	}
+
+	return result, nil
+}
+
+// isSequence returns true when keys are exactly "0", "1", ..., "n-1".
+func isSequence(children map[string]*unflattenNode) bool {
+	for name := range children {
+		index, err := strconv.Atoi(name)
+		if err != nil || index < 0 || index >= len(children) || strconv.Itoa(index) != name {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,142 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"testing"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnflatten(t *testing.T) {
+	t.Run("should unflatten using default options", func(t *testing.T) {
+		flat := map[string]interface{}{
+			"db.pool.size":  10,
+			"db.name":       "name",
+			"items.0.price": 3,
+			"items.1.price": 4,
+			"ports.8080":    "http",
+			"top":           true,
+		}
+		// when
+		actual, err := mapify.Unflatten(flat, mapify.FlattenOptions{})
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"db": map[string]interface{}{
+				"pool": map[string]interface{}{"size": 10},
+				"name": "name",
+			},
+			"items": []interface{}{
+				map[string]interface{}{"price": 3},
+				map[string]interface{}{"price": 4},
+			},
+			"ports": map[string]interface{}{"8080": "http"},
+			"top":   true,
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should unflatten keys with indexes in brackets", func(t *testing.T) {
+		flat := map[string]interface{}{
+			"items[0]_price": 3,
+			"items[1]_price": 4,
+			"matrix[0][1]":   "b",
+			"matrix[0][0]":   "a",
+			"labels_0":       "zero",
+		}
+		// when
+		actual, err := mapify.Unflatten(flat, mapify.FlattenOptions{Separator: "_", IndexStyle: mapify.IndexInBrackets})
+		// then
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"price": 3},
+				map[string]interface{}{"price": 4},
+			},
+			"matrix": []interface{}{
+				[]interface{}{"a", "b"},
+			},
+			"labels": map[string]interface{}{"0": "zero"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should be the inverse of Flatten", func(t *testing.T) {
+		nested := map[string]interface{}{
+			"user": map[string]interface{}{
+				"address": map[string]interface{}{"city": "X"},
+			},
+			"items": []interface{}{
+				map[string]interface{}{"price": 3},
+			},
+			"empty": []interface{}{},
+		}
+
+		for _, style := range []mapify.IndexStyle{mapify.IndexAsKey, mapify.IndexInBrackets} {
+			options := mapify.FlattenOptions{IndexStyle: style}
+			actual, err := mapify.Unflatten(mapify.Flatten(nested, options), options)
+			require.NoError(t, err)
+			assert.Equal(t, nested, actual)
+		}
+	})
+
+	t.Run("should return conflict error", func(t *testing.T) {
+		tests := map[string]struct {
+			flat          map[string]interface{}
+			options       mapify.FlattenOptions
+			expectedPaths []string
+		}{
+			"leaf and parent": {
+				flat:          map[string]interface{}{"db.pool": 1, "db.pool.size": 10},
+				expectedPaths: []string{`"db.pool"`, `"db.pool.size"`},
+			},
+			"parent and leaf": {
+				flat:          map[string]interface{}{"a.b.c": 1, "a.b": 2},
+				expectedPaths: []string{`"a.b"`, `"a.b.c"`},
+			},
+			"index and name": {
+				flat:          map[string]interface{}{"a[0]": 1, "a.b": 2},
+				options:       mapify.FlattenOptions{IndexStyle: mapify.IndexInBrackets},
+				expectedPaths: []string{`"a.b"`, `"a[0]"`},
+			},
+			"the same index": {
+				flat:          map[string]interface{}{"a[0]": 1, "a[00]": 2},
+				options:       mapify.FlattenOptions{IndexStyle: mapify.IndexInBrackets},
+				expectedPaths: []string{`"a[0]"`, `"a[00]"`},
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				actual, err := mapify.Unflatten(test.flat, test.options)
+				assert.Nil(t, actual)
+				require.ErrorIs(t, err, mapify.ErrKeyConflict)
+
+				for _, path := range test.expectedPaths {
+					assert.Contains(t, err.Error(), path)
+				}
+			})
+		}
+	})
+
+	t.Run("should return error for invalid indexes", func(t *testing.T) {
+		tests := map[string]map[string]interface{}{
+			"gap in indexes":      {"a[1]": 1},
+			"negative index":      {"a[-1]": 1},
+			"not a number":        {"a[x]": 1},
+			"index without name":  {"[0]": 1},
+			"not closed brackets": {"a[0": 1},
+		}
+
+		for name, flat := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := mapify.Unflatten(flat, mapify.FlattenOptions{IndexStyle: mapify.IndexInBrackets})
+				assert.Error(t, err)
+			})
+		}
+	})
+}
@@ -0,0 +1,233 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NestingStyle specifies how keys of nested maps are encoded in url.Values.
+type NestingStyle int
+
+const (
+	// NestingBrackets encodes nested keys in brackets, for example "a[b]".
+	NestingBrackets NestingStyle = iota
+	// NestingDotted joins nested keys with dots, for example "a.b".
+	NestingDotted
+)
+
+// SliceStyle specifies how slices of scalar values are encoded in url.Values.
+type SliceStyle int
+
+const (
+	// SliceRepeated adds a value for each element under the same key, for example "a=1&a=2".
+	SliceRepeated SliceStyle = iota
+	// SliceCommaJoined joins elements with comma, for example "a=1,2".
+	SliceCommaJoined
+	// SliceIndexed adds each element under a key with index, for example "a[0]=1&a[1]=2".
+	SliceIndexed
+)
+
+// ValuesOptions configures MapValues.
+type ValuesOptions struct {
+	Nesting NestingStyle
+	// Slices specifies encoding of slices of scalar values. Slices of maps are always indexed.
+	Slices SliceStyle
+	// Format formats scalar values. Default is FormatValue.
+	Format Formatter
+}
+
+// Formatter formats a scalar value to string. It is not run for nil values, which are omitted.
+type Formatter func(value interface{}) (string, error)
+
+// flatEncoding specifies how nested values are encoded using flat keys.
+type flatEncoding struct {
+	nestedKey func(prefix, name string) string
+	indexKey  func(prefix string, index int) string
+	slices    SliceStyle
+	format    Formatter
+}
+
+func (o ValuesOptions) encoding() flatEncoding {
+	format := o.Format
+	if format == nil {
+		format = FormatValue
+	}
+
+	return flatEncoding{
+		nestedKey: func(prefix, name string) string {
+			return nestedValuesKey(prefix, name, o.Nesting)
+		},
+		indexKey: func(prefix string, index int) string {
+			return prefix + "[" + strconv.Itoa(index) + "]"
+		},
+		slices: o.Slices,
+		format: format,
+	}
+}
+
+// MapValues maps v using MapAny and encodes the result as url.Values, which can be used to build a query string
+// or a form. v must be converted to a map, so it has to be a struct or a map with string key. Keys are controlled
+// by Filter and Rename as usual.
+//
+// Scalar values are formatted with options.Format. Nil values, empty slices and empty maps are omitted.
+func (i Mapper) MapValues(v interface{}, options ValuesOptions) (url.Values, error) {
+	return i.mapFlat(v, "", options.encoding())
+}
+
+// mapFlat maps v using MapAny and encodes the result using flat keys starting with root key.
+func (i Mapper) mapFlat(v interface{}, root string, enc flatEncoding) (url.Values, error) {
+	mapped, err := i.MapAny(v)
+	if err != nil {
+		return nil, err
+	}
+
+	value := reflect.ValueOf(mapped)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("%T was not converted to a map", v)
+	}
+
+	values := url.Values{}
+	if err = encodeValues(values, root, value, enc); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func encodeValues(values url.Values, key string, value reflect.Value, enc flatEncoding) error {
+	value = dereferenceInterface(value)
+
+	switch {
+	case !isContainer(value):
+		formatted, ok, err := formatScalar(value, enc.format)
+		if err != nil {
+			return fmt.Errorf("formatting %s failed: %w", key, err)
+		}
+
+		if ok {
+			values.Add(key, formatted)
+		}
+	case value.Kind() == reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			nestedKey := enc.nestedKey(key, iter.Key().String())
+			if err := encodeValues(values, nestedKey, iter.Value(), enc); err != nil {
+				return err
+			}
+		}
+	case enc.slices == SliceIndexed || containsContainers(value):
+		for j := 0; j < value.Len(); j++ {
+			if err := encodeValues(values, enc.indexKey(key, j), value.Index(j), enc); err != nil {
+				return err
+			}
+		}
+	default:
+		var elements []string
+
+		for j := 0; j < value.Len(); j++ {
+			formatted, ok, err := formatScalar(value.Index(j), enc.format)
+			if err != nil {
+				return fmt.Errorf("formatting %s[%d] failed: %w", key, j, err)
+			}
+
+			if ok {
+				elements = append(elements, formatted)
+			}
+		}
+
+		if len(elements) == 0 {
+			return nil
+		}
+
+		if enc.slices == SliceCommaJoined {
+			values.Add(key, strings.Join(elements, ","))
+		} else {
+			values[key] = append(values[key], elements...)
+		}
+	}
+
+	return nil
+}
+
+func nestedValuesKey(prefix, key string, nesting NestingStyle) string {
+	switch {
+	case prefix == "":
+		return key
+	case nesting == NestingDotted:
+		return prefix + "." + key
+	default:
+		return prefix + "[" + key + "]"
+	}
+}
+
+func containsContainers(slice reflect.Value) bool {
+	for j := 0; j < slice.Len(); j++ {
+		if isContainer(dereferenceInterface(slice.Index(j))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dereferenceInterface(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	return value
+}
+
+// formatScalar formats value to string. ok is false for nil values.
+func formatScalar(value reflect.Value, format Formatter) (_ string, ok bool, _ error) {
+	value = dereferenceInterface(value)
+
+	if !value.IsValid() || ((value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil()) {
+		return "", false, nil
+	}
+
+	formatted, err := format(value.Interface())
+
+	return formatted, err == nil, err
+}
+
+// FormatValue is the default Formatter. Values implementing encoding.TextMarshaler (such as time.Time) are
+// formatted using MarshalText, []byte is converted to string, and other values are formatted with fmt.Sprint.
+// Pointers are dereferenced, and nil pointers are formatted as empty string.
+func FormatValue(value interface{}) (string, error) {
+	reflectValue := reflect.ValueOf(value)
+
+	for reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return "", nil
+		}
+
+		if _, ok := reflectValue.Interface().(encoding.TextMarshaler); ok {
+			break
+		}
+
+		reflectValue = reflectValue.Elem()
+	}
+
+	if reflectValue.IsValid() {
+		value = reflectValue.Interface()
+	}
+
+	switch v := value.(type) {
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+
+		return string(text), err
+	case []byte:
+		return string(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
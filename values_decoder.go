@@ -0,0 +1,357 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeError is returned when a value cannot be decoded into a struct field.
+type DecodeError struct {
+	// Path of the struct field, for example ".Items[0].Price"
+	Path string
+	// Key is the source key, for example "Items[0][Price]"
+	Key string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %s from key %q failed: %s", e.Path, e.Key, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// DecodeValues decodes url.Values (such as query string or form data) into the struct pointed to by target. It is
+// the inverse of MapValues: keys are built from struct fields using the same Filter and Rename, and the same
+// options. MapValue is not used.
+//
+// Strings are converted to bools, numbers, time.Duration and types implementing encoding.TextUnmarshaler
+// (such as time.Time). Nested structs are decoded when ShouldConvert returns true. Maps with string key are decoded
+// without running Filter and Rename for their entries. Keys which do not match any field are ignored.
+//
+// When a value cannot be converted, *DecodeError containing field path and key is returned.
+func (i Mapper) DecodeValues(values url.Values, target interface{}, options ValuesOptions) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a non-nil pointer to struct, got %T", target)
+	}
+
+	return i.decodeFlat(values, value, "", options.encoding())
+}
+
+// decodeFlat decodes values with flat keys starting with root key into the struct pointed to by target.
+func (i Mapper) decodeFlat(values url.Values, target reflect.Value, root string, enc flatEncoding) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	decoder := valuesDecoder{Mapper: i.newInstance(), values: values, keys: keys, flatEncoding: enc}
+	_, err := decoder.decodeStruct("", root, target.Elem())
+
+	return err
+}
+
+type valuesDecoder struct {
+	Mapper
+	flatEncoding
+	values url.Values
+	keys   []string // sorted keys of values
+}
+
+// decodeStruct returns true when at least one field was decoded.
+func (d valuesDecoder) decodeStruct(path, key string, value reflect.Value) (bool, error) {
+	reflectType := value.Type()
+	decoded := false
+
+	for j := 0; j < reflectType.NumField(); j++ {
+		field := reflectType.Field(j)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		element, err := d.prepare(fieldPath, Element{name: field.Name, Value: value.Field(j), field: &field})
+		if err != nil {
+			return false, err
+		}
+
+		accepted, err := d.Filter(fieldPath, element)
+		if err != nil {
+			return false, fmt.Errorf("Filter failed: %w", err)
+		}
+
+		if !accepted {
+			continue
+		}
+
+		renamed, err := d.Rename(fieldPath, element)
+		if err != nil {
+			return false, fmt.Errorf("Rename failed: %w", err)
+		}
+
+		fieldDecoded, err := d.decodeValue(fieldPath, d.nestedKey(key, renamed), value.Field(j))
+		if err != nil {
+			return false, err
+		}
+
+		decoded = decoded || fieldDecoded
+	}
+
+	return decoded, nil
+}
+
+func (d valuesDecoder) decodeValue(path, key string, value reflect.Value) (bool, error) {
+	reflectType := value.Type()
+
+	if isScalarType(reflectType) {
+		strs := d.values[key]
+		if len(strs) == 0 {
+			return false, nil
+		}
+
+		return true, d.decodeScalar(path, key, strs[0], value)
+	}
+
+	if !d.hasKeysWithPrefix(key) {
+		return false, nil // nothing to decode, which also stops recursion for self-referencing types
+	}
+
+	switch reflectType.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(reflectType.Elem())
+
+		decoded, err := d.decodeValue(path, key, elem.Elem())
+		if decoded && err == nil {
+			value.Set(elem)
+		}
+
+		return decoded, err
+	case reflect.Struct:
+		shouldConvert, err := d.ShouldConvert(path, value)
+		if err != nil {
+			return false, fmt.Errorf("ShouldConvert failed: %w", err)
+		}
+
+		if !shouldConvert {
+			return false, nil
+		}
+
+		return d.decodeStruct(path, key, value)
+	case reflect.Slice:
+		return d.decodeSlice(path, key, value)
+	case reflect.Map:
+		return d.decodeMap(path, key, value)
+	case reflect.Interface:
+		strs := d.values[key]
+		if len(strs) == 0 || reflectType.NumMethod() > 0 {
+			return false, nil
+		}
+
+		value.Set(reflect.ValueOf(strs[0]))
+
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func (d valuesDecoder) decodeSlice(path, key string, value reflect.Value) (bool, error) {
+	elemType := value.Type().Elem()
+
+	if !isScalarType(elemType) || d.slices == SliceIndexed {
+		slice := reflect.MakeSlice(value.Type(), 0, 0)
+
+		for j := 0; ; j++ {
+			elem := reflect.New(elemType).Elem()
+
+			decoded, err := d.decodeValue(slicePath(path, j), d.indexKey(key, j), elem)
+			if err != nil {
+				return false, err
+			}
+
+			if !decoded {
+				break
+			}
+
+			slice = reflect.Append(slice, elem)
+		}
+
+		if slice.Len() == 0 {
+			return false, nil
+		}
+
+		value.Set(slice)
+
+		return true, nil
+	}
+
+	strs := d.values[key]
+	if d.slices == SliceCommaJoined && len(strs) > 0 {
+		strs = strings.Split(strs[0], ",")
+	}
+
+	if len(strs) == 0 {
+		return false, nil
+	}
+
+	slice := reflect.MakeSlice(value.Type(), len(strs), len(strs))
+
+	for j, str := range strs {
+		if err := d.decodeScalar(slicePath(path, j), key, str, slice.Index(j)); err != nil {
+			return false, err
+		}
+	}
+
+	value.Set(slice)
+
+	return true, nil
+}
+
+func (d valuesDecoder) decodeMap(path, key string, value reflect.Value) (bool, error) {
+	reflectType := value.Type()
+	if reflectType.Key().Kind() != reflect.String || !isScalarType(reflectType.Elem()) {
+		return false, nil
+	}
+
+	const marker = "\x00"
+
+	prefix, suffix, _ := cut(d.nestedKey(key, marker), marker)
+
+	result := reflect.MakeMap(reflectType)
+
+	for valuesKey, strs := range d.values {
+		if len(strs) == 0 || !strings.HasPrefix(valuesKey, prefix) || !strings.HasSuffix(valuesKey, suffix) {
+			continue
+		}
+
+		mapKey := strings.TrimSuffix(strings.TrimPrefix(valuesKey, prefix), suffix)
+		if mapKey == "" || strings.ContainsAny(mapKey, "[]") {
+			continue // nested keys are not supported
+		}
+
+		elem := reflect.New(reflectType.Elem()).Elem()
+		if err := d.decodeScalar(path+"."+mapKey, valuesKey, strs[0], elem); err != nil {
+			return false, err
+		}
+
+		result.SetMapIndex(reflect.ValueOf(mapKey).Convert(reflectType.Key()), elem)
+	}
+
+	if result.Len() == 0 {
+		return false, nil
+	}
+
+	value.Set(result)
+
+	return true, nil
+}
+
+// hasKeysWithPrefix returns true when at least one key of values starts with prefix. Keys of nested values
+// always start with the key of their parent.
+func (d valuesDecoder) hasKeysWithPrefix(prefix string) bool {
+	j := sort.SearchStrings(d.keys, prefix)
+
+	return j < len(d.keys) && strings.HasPrefix(d.keys[j], prefix)
+}
+
+// isScalarType returns true for types decoded from a single string.
+func isScalarType(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	default:
+		return false
+	}
+}
+
+func (d valuesDecoder) decodeScalar(path, key, str string, value reflect.Value) error {
+	if err := parseScalar(str, value); err != nil {
+		return &DecodeError{Path: path, Key: key, Err: err}
+	}
+
+	return nil
+}
+
+// parseScalar parses str into value, which type must be a scalar type.
+func parseScalar(str string, value reflect.Value) error {
+	reflectType := value.Type()
+
+	if reflect.PtrTo(reflectType).Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str))
+	}
+
+	if reflectType == durationType {
+		duration, err := time.ParseDuration(str)
+		value.SetInt(int64(duration))
+
+		return err
+	}
+
+	switch reflectType.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		value.SetBool(b)
+
+		return err
+	case reflect.String:
+		value.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, reflectType.Bits())
+		value.SetInt(n)
+
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(str, 10, reflectType.Bits())
+		value.SetUint(n)
+
+		return err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(str, reflectType.Bits())
+		value.SetFloat(f)
+
+		return err
+	case reflect.Slice:
+		value.SetBytes([]byte(str))
+	default:
+		return fmt.Errorf("unsupported type %s", reflectType)
+	}
+
+	return nil
+}
+
+// cut is strings.Cut, which is not available in Go 1.17.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
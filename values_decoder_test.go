@@ -0,0 +1,187 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type form struct {
+	Name     string
+	Age      int
+	Ratio    float64
+	Active   bool
+	Unsigned uint8
+	Page     *int
+	Timeout  time.Duration
+	Since    time.Time
+	Tags     []string
+	Numbers  []int
+	Items    []formItem
+	Nested   formItem
+	Pointer  *formItem
+	Labels   map[string]string
+	Bytes    []byte
+	Ignored  string
+}
+
+type formItem struct {
+	Price int
+}
+
+type formNode struct {
+	Name     string
+	Next     *formNode
+	Children []formNode
+}
+
+func TestMapper_DecodeValues(t *testing.T) {
+	page := 3
+	expected := form{
+		Name:     "n",
+		Age:      30,
+		Ratio:    0.5,
+		Active:   true,
+		Unsigned: 8,
+		Page:     &page,
+		Timeout:  time.Second,
+		Since:    time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:     []string{"a", "b"},
+		Numbers:  []int{1, 2},
+		Items:    []formItem{{Price: 1}, {Price: 2}},
+		Nested:   formItem{Price: 3},
+		Pointer:  &formItem{Price: 4},
+		Labels:   map[string]string{"k": "v"},
+		Bytes:    []byte("bytes"),
+	}
+
+	mapper := mapify.Mapper{
+		ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+			return path != ".Since", nil
+		},
+		Filter: func(path string, e mapify.Element) (bool, error) {
+			return path != ".Ignored", nil
+		},
+		Rename: func(path string, e mapify.Element) (string, error) {
+			return strings.ToLower(e.Name()), nil
+		},
+		MapValue: func(path string, e mapify.Element) (interface{}, error) {
+			if path == ".Timeout" {
+				return e.Interface().(time.Duration).String(), nil
+			}
+
+			if path == ".Bytes" {
+				return string(e.Bytes()), nil
+			}
+
+			return e.Interface(), nil
+		},
+	}
+
+	t.Run("should decode values encoded by MapValues", func(t *testing.T) {
+		options := []mapify.ValuesOptions{
+			{},
+			{Nesting: mapify.NestingDotted, Slices: mapify.SliceCommaJoined},
+			{Slices: mapify.SliceIndexed},
+		}
+
+		for _, opts := range options {
+			values, err := mapper.MapValues(expected, opts)
+			require.NoError(t, err)
+
+			var actual form
+			// when
+			err = mapper.DecodeValues(values, &actual, opts)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("should leave fields without keys untouched", func(t *testing.T) {
+		actual := form{Name: "untouched", Pointer: nil}
+		// when
+		err := mapper.DecodeValues(url.Values{"age": {"1"}, "ignored": {"v"}}, &actual, mapify.ValuesOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, form{Name: "untouched", Age: 1}, actual)
+	})
+
+	t.Run("should decode self-referencing type", func(t *testing.T) {
+		values := url.Values{"Name": {"a"}, "Next[Name]": {"b"}, "Children[0][Next][Name]": {"c"}}
+		var actual formNode
+		// when
+		err := mapify.Mapper{}.DecodeValues(values, &actual, mapify.ValuesOptions{})
+		// then
+		require.NoError(t, err)
+		expected := formNode{
+			Name:     "a",
+			Next:     &formNode{Name: "b"},
+			Children: []formNode{{Next: &formNode{Name: "c"}}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should return error with path and key", func(t *testing.T) {
+		values := url.Values{"items[1][price]": {"x"}, "items[0][price]": {"1"}}
+		// when
+		err := mapper.DecodeValues(values, &form{}, mapify.ValuesOptions{})
+		// then
+		var decodeError *mapify.DecodeError
+		require.True(t, errors.As(err, &decodeError))
+		assert.Equal(t, ".Items[1].Price", decodeError.Path)
+		assert.Equal(t, "items[1][price]", decodeError.Key)
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+	})
+
+	t.Run("should return error for invalid values", func(t *testing.T) {
+		tests := map[string]url.Values{
+			"bool":     {"active": {"maybe"}},
+			"overflow": {"unsigned": {"256"}},
+			"time":     {"since": {"yesterday"}},
+			"duration": {"timeout": {"long"}},
+			"float":    {"ratio": {"half"}},
+		}
+
+		for name, values := range tests {
+			t.Run(name, func(t *testing.T) {
+				err := mapper.DecodeValues(values, &form{}, mapify.ValuesOptions{})
+				var decodeError *mapify.DecodeError
+				assert.True(t, errors.As(err, &decodeError))
+			})
+		}
+	})
+
+	t.Run("should return error when target is not a pointer to struct", func(t *testing.T) {
+		targets := []interface{}{nil, form{}, (*form)(nil), &[]string{}}
+
+		for _, target := range targets {
+			err := mapify.Mapper{}.DecodeValues(url.Values{}, target, mapify.ValuesOptions{})
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("should return error when Rename returned error", func(t *testing.T) {
+		givenError := stringError("err")
+		mapper := mapify.Mapper{
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return "", givenError
+			},
+		}
+		// when
+		err := mapper.DecodeValues(url.Values{}, &form{}, mapify.ValuesOptions{})
+		// then
+		assert.ErrorIs(t, err, givenError)
+	})
+}
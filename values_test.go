@@ -0,0 +1,136 @@
+// (c) 2022 Jacek Olszak
+// This code is licensed under MIT license (see LICENSE for details)
+
+package mapify_test
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgopher/mapify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapper_MapValues(t *testing.T) {
+	type item struct {
+		Price int
+	}
+
+	type filter struct {
+		Name   string
+		Page   *int
+		Tags   []string
+		Items  []item
+		Nested struct{ Field bool }
+		Since  time.Time
+		Empty  []string
+	}
+
+	page := 2
+	value := filter{
+		Name:   "n",
+		Page:   &page,
+		Tags:   []string{"a", "b"},
+		Items:  []item{{Price: 1}, {Price: 2}},
+		Nested: struct{ Field bool }{Field: true},
+		Since:  time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	mapper := mapify.Mapper{
+		ShouldConvert: func(path string, value reflect.Value) (bool, error) {
+			return path != ".Since", nil
+		},
+	}
+
+	t.Run("should encode using default options", func(t *testing.T) {
+		actual, err := mapper.MapValues(value, mapify.ValuesOptions{})
+		require.NoError(t, err)
+		expected := url.Values{
+			"Name":            {"n"},
+			"Page":            {"2"},
+			"Tags":            {"a", "b"},
+			"Items[0][Price]": {"1"},
+			"Items[1][Price]": {"2"},
+			"Nested[Field]":   {"true"},
+			"Since":           {"2022-01-02T03:04:05Z"},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should encode dotted keys and comma joined slices", func(t *testing.T) {
+		actual, err := mapper.MapValues(value, mapify.ValuesOptions{
+			Nesting: mapify.NestingDotted,
+			Slices:  mapify.SliceCommaJoined,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a,b"}, actual["Tags"])
+		assert.Equal(t, []string{"1"}, actual["Items[0].Price"])
+		assert.Equal(t, []string{"true"}, actual["Nested.Field"])
+	})
+
+	t.Run("should encode indexed slices", func(t *testing.T) {
+		actual, err := mapper.MapValues(value, mapify.ValuesOptions{Slices: mapify.SliceIndexed})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, actual["Tags[0]"])
+		assert.Equal(t, []string{"b"}, actual["Tags[1]"])
+	})
+
+	t.Run("should use Filter and Rename", func(t *testing.T) {
+		mapper := mapify.Mapper{
+			Filter: func(path string, e mapify.Element) (bool, error) {
+				return path == ".Name" || path == ".Nested" || path == ".Nested.Field", nil
+			},
+			Rename: func(path string, e mapify.Element) (string, error) {
+				return strings.ToLower(e.Name()), nil
+			},
+		}
+		// when
+		actual, err := mapper.MapValues(value, mapify.ValuesOptions{})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "n&nested%5Bfield%5D=true", strings.TrimPrefix(actual.Encode(), "name="))
+	})
+
+	t.Run("should omit nil values", func(t *testing.T) {
+		v := map[string]interface{}{"nil": nil, "page": (*int)(nil)}
+		actual, err := mapify.Mapper{}.MapValues(v, mapify.ValuesOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	t.Run("should return error when value is not converted to map", func(t *testing.T) {
+		_, err := mapify.Mapper{}.MapValues([]string{"a"}, mapify.ValuesOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatValue(t *testing.T) {
+	text := "text"
+	date := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"string":                     {value: "text", expected: "text"},
+		"pointer":                    {value: &text, expected: "text"},
+		"bytes":                      {value: []byte("text"), expected: "text"},
+		"text marshaler":             {value: date, expected: "2022-01-02T03:04:05Z"},
+		"pointer to text marshaler":  {value: &date, expected: "2022-01-02T03:04:05Z"},
+		"nil pointer":                {value: (*string)(nil), expected: ""},
+		"nil pointer to marshaler":   {value: (*time.Time)(nil), expected: ""},
+		"nil pointer to nil pointer": {value: (**time.Time)(nil), expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := mapify.FormatValue(test.value)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}